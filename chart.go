@@ -0,0 +1,228 @@
+package bussola
+
+import (
+	"math"
+	"time"
+)
+
+// ChartType is the kind of drawing used by a chart
+type ChartType string
+
+const (
+	ChartLine    ChartType = "line"
+	ChartBar     ChartType = "bar"
+	ChartArea    ChartType = "area"
+	ChartPie     ChartType = "pie"
+	ChartDonut   ChartType = "donut"
+	ChartScatter ChartType = "scatter"
+)
+
+// Valid reports whether the chart type is one of the supported types
+func (t ChartType) Valid() bool {
+	switch t {
+	case ChartLine, ChartBar, ChartArea, ChartPie, ChartDonut, ChartScatter:
+		return true
+	}
+	return false
+}
+
+// Circular reports whether the chart is drawn as a circle (pie or donut)
+func (t ChartType) Circular() bool {
+	return t == ChartPie || t == ChartDonut
+}
+
+// AxisType is the kind of values on the x-axis
+type AxisType string
+
+const (
+	AxisCategory AxisType = "category"
+	AxisTime     AxisType = "time"
+)
+
+// XAxis describes the horizontal axis shared by every series of a chart
+type XAxis struct {
+	Type       AxisType    `json:"type"`
+	Title      string      `json:"title,omitempty"`
+	Categories []string    `json:"categories,omitempty"`
+	Times      []time.Time `json:"times,omitempty"`
+}
+
+// Len returns the number of points on the axis
+func (a *XAxis) Len() int {
+	if a.Type == AxisTime {
+		return len(a.Times)
+	}
+	return len(a.Categories)
+}
+
+// YAxis describes the numeric vertical axis of a chart
+type YAxis struct {
+	Title string   `json:"title,omitempty"`
+	Unit  string   `json:"unit,omitempty"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+}
+
+// Series is a named list of values drawn by a chart. X is only used by
+// scatter charts, where each point has its own x value.
+type Series struct {
+	Name  string    `json:"name"`
+	Color string    `json:"color,omitempty"`
+	Data  []float64 `json:"data"`
+	X     []float64 `json:"x,omitempty"`
+}
+
+// AddSeries appends a series to the chart
+func (c *Chart) AddSeries(name, color string, data ...float64) {
+	c.Series = append(c.Series, Series{Name: name, Color: color, Data: data})
+}
+
+// SetCategories sets a categorical x-axis with the given labels
+func (c *Chart) SetCategories(labels ...string) {
+	c.XAxis = &XAxis{Type: AxisCategory, Categories: labels}
+}
+
+// SetTimes sets a time x-axis with the given instants
+func (c *Chart) SetTimes(times ...time.Time) {
+	c.XAxis = &XAxis{Type: AxisTime, Times: times}
+}
+
+// Normalized returns a copy of the chart where Series and XAxis are filled
+// from the untyped Data and Options fields when they are not set. A flat
+// list of numbers becomes a single series named after the chart, and
+// Options["xAxis"] labels become a categorical axis.
+func (c *Chart) Normalized() *Chart {
+	n := *c
+	opts, _ := c.Options.(map[string]any)
+
+	if len(n.Series) == 0 {
+		if values, ok := toFloats(c.Data); ok {
+			color, _ := opts["color"].(string)
+			n.Series = []Series{{Name: c.Title, Color: color, Data: values}}
+		}
+	}
+
+	if n.XAxis == nil {
+		if labels, ok := toStrings(opts["xAxis"]); ok {
+			n.XAxis = &XAxis{Type: AxisCategory, Categories: labels}
+		}
+	}
+
+	if n.Series == nil {
+		n.Series = []Series{}
+	}
+	return &n
+}
+
+// Validate checks the chart type, axes and series against each other
+func (c *Chart) Validate() error {
+	v := &validator{}
+	n := c.Normalized()
+
+	if !n.Type.Valid() {
+		v.add("chartType", "unsupported chart type %q", n.Type)
+	}
+	if n.Stacked && (n.Type.Circular() || n.Type == ChartScatter) {
+		v.add("stacked", "%s charts cannot be stacked", n.Type)
+	}
+	if n.Type.Circular() && len(n.Series) > 1 {
+		v.add("series", "%s charts take a single series, got %d", n.Type, len(n.Series))
+	}
+
+	if a := n.XAxis; a != nil {
+		switch a.Type {
+		case AxisCategory:
+			if len(a.Times) > 0 {
+				v.add("xAxis.times", "categorical axis cannot have times")
+			}
+		case AxisTime:
+			if len(a.Categories) > 0 {
+				v.add("xAxis.categories", "time axis cannot have categories")
+			}
+			for i := 1; i < len(a.Times); i++ {
+				if a.Times[i].Before(a.Times[i-1]) {
+					v.add("xAxis.times", "times must be in ascending order")
+					break
+				}
+			}
+		default:
+			v.add("xAxis.type", "unsupported axis type %q", a.Type)
+		}
+	}
+
+	if a := n.YAxis; a != nil && a.Min != nil && a.Max != nil && *a.Min >= *a.Max {
+		v.add("yAxis", "min %g must be less than max %g", *a.Min, *a.Max)
+	}
+
+	for i, s := range n.Series {
+		field := joinPath("series", indexPath(i))
+		if s.Color != "" {
+			if _, err := ParseHexColor(s.Color); err != nil {
+				v.add(field+".color", "%v", err)
+			}
+		}
+		if n.XAxis != nil && n.XAxis.Len() > 0 && len(s.Data) != n.XAxis.Len() {
+			v.add(field+".data", "has %d points but the x-axis has %d labels", len(s.Data), n.XAxis.Len())
+		}
+		if s.X != nil && n.Type != ChartScatter {
+			v.add(field+".x", "x values are only used by scatter charts")
+		}
+		if s.X != nil && len(s.X) != len(s.Data) {
+			v.add(field+".x", "has %d values but data has %d", len(s.X), len(s.Data))
+		}
+		for j, value := range s.Data {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				v.add(joinPath(field+".data", indexPath(j)), "must be a finite number")
+			} else if n.Type.Circular() && value < 0 {
+				v.add(joinPath(field+".data", indexPath(j)), "%s charts cannot have negative values", n.Type)
+			}
+		}
+	}
+
+	return v.err()
+}
+
+func toFloats(data any) ([]float64, bool) {
+	switch v := data.(type) {
+	case []float64:
+		return v, true
+	case []int:
+		out := make([]float64, len(v))
+		for i, n := range v {
+			out[i] = float64(n)
+		}
+		return out, true
+	case []any:
+		out := make([]float64, len(v))
+		for i, item := range v {
+			switch n := item.(type) {
+			case float64:
+				out[i] = n
+			case int:
+				out[i] = float64(n)
+			default:
+				return nil, false
+			}
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+func toStrings(data any) ([]string, bool) {
+	switch v := data.(type) {
+	case []string:
+		return v, true
+	case []any:
+		out := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			out[i] = s
+		}
+		return out, true
+	}
+	return nil, false
+}
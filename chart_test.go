@@ -0,0 +1,102 @@
+package bussola
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestChartValidate(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		chart  func() *Chart
+		fields []string
+	}{
+		{"valid line", func() *Chart {
+			c := NewChart("revenue", ChartLine)
+			c.SetCategories("jan", "feb", "mar")
+			c.AddSeries("2024", "#336699", 1, 2, 3)
+			return c
+		}, nil},
+		{"more points than labels", func() *Chart {
+			c := NewChart("revenue", ChartLine)
+			c.SetCategories("a", "b", "c", "d", "e")
+			c.AddSeries("2024", "", 1, 2, 3, 4, 5, 6)
+			return c
+		}, []string{"series[0].data"}},
+		{"pie with two series", func() *Chart {
+			c := NewChart("share", ChartPie)
+			c.AddSeries("a", "", 1)
+			c.AddSeries("b", "", 2)
+			return c
+		}, []string{"series"}},
+		{"donut with two series", func() *Chart {
+			c := NewChart("share", ChartDonut)
+			c.AddSeries("a", "", 1)
+			c.AddSeries("b", "", 2)
+			return c
+		}, []string{"series"}},
+		{"stacked pie", func() *Chart {
+			c := NewChart("share", ChartPie)
+			c.Stacked = true
+			c.AddSeries("a", "", 1)
+			return c
+		}, []string{"stacked"}},
+		{"stacked scatter", func() *Chart {
+			c := NewChart("points", ChartScatter)
+			c.Stacked = true
+			c.Series = []Series{{Name: "a", Data: []float64{1}, X: []float64{1}}}
+			return c
+		}, []string{"stacked"}},
+		{"unordered times", func() *Chart {
+			c := NewChart("visits", ChartArea)
+			c.SetTimes(day, day.AddDate(0, 0, 2), day.AddDate(0, 0, 1))
+			c.AddSeries("visits", "", 1, 2, 3)
+			return c
+		}, []string{"xAxis.times"}},
+		{"invalid color", func() *Chart {
+			c := NewChart("revenue", ChartBar)
+			c.AddSeries("a", "#12", 1)
+			c.AddSeries("b", "blue", 1)
+			return c
+		}, []string{"series[0].color", "series[1].color"}},
+		{"not a number", func() *Chart {
+			c := NewChart("revenue", ChartLine)
+			c.AddSeries("a", "", 1, math.NaN(), math.Inf(1))
+			return c
+		}, []string{"series[0].data[1]", "series[0].data[2]"}},
+		{"unknown type", func() *Chart {
+			return NewChart("revenue", ChartType("radar"))
+		}, []string{"chartType"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.chart().Validate()
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("Validate() = %v, want a *ValidationError", err)
+			}
+			var got []string
+			for _, fe := range ve.Errors {
+				got = append(got, fe.Field)
+			}
+			if len(got) != len(tt.fields) {
+				t.Fatalf("Validate() fields = %q, want %q", got, tt.fields)
+			}
+			for i := range got {
+				if got[i] != tt.fields[i] {
+					t.Errorf("Validate() fields = %q, want %q", got, tt.fields)
+					break
+				}
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package bussola
 
 // Component represents any visual element that can be added to the dashboard
 type Component interface {
+	// MinSize is the smallest size ComputeLayout may give the component.
+	// It is not the size set by Resize.
 	MinSize() Size
 	Resize(size Size)
 	Position() Position
@@ -0,0 +1,206 @@
+package bussola
+
+import "iter"
+
+// Add places each object in the first free 1x1 slot, in reading order.
+// Objects that do not fit are ignored; use PlaceNext to get the reason.
+func (g *Grid) Add(objects ...CanvasObject) {
+	for _, obj := range objects {
+		g.AddNext(obj)
+	}
+}
+
+// Remove takes every cell holding obj out of the grid
+func (g *Grid) Remove(obj CanvasObject) {
+	for cell := range g.Items() {
+		if cell.Content == Component(obj) {
+			g.vacate(cell)
+		}
+	}
+}
+
+// Objects returns the contents of the grid that are canvas objects, in
+// reading order
+func (g *Grid) Objects() []CanvasObject {
+	objects := []CanvasObject{}
+	for cell := range g.Items() {
+		if obj, ok := cell.Content.(CanvasObject); ok {
+			objects = append(objects, obj)
+		}
+	}
+	return objects
+}
+
+// Items iterates over the cells of the grid in reading order of their
+// anchors when iteration starts. Nested grids are not entered. The grid may
+// be edited while iterating: each cell is yielded at most once, cells
+// removed before they are reached are skipped and cells added are not
+// yielded.
+func (g *Grid) Items() iter.Seq[*GridCell] {
+	return func(yield func(*GridCell) bool) {
+		type anchored struct {
+			cell     *GridCell
+			row, col int
+		}
+		var cells []anchored
+		for i := range g.Cells {
+			for j, cell := range g.Cells[i] {
+				if cell != nil {
+					cells = append(cells, anchored{cell, i, j})
+				}
+			}
+		}
+
+		for _, a := range cells {
+			if !g.holds(a.cell, a.row, a.col) {
+				continue
+			}
+			if !yield(a.cell) {
+				return
+			}
+		}
+	}
+}
+
+// holds reports whether cell is still in the grid, at the slot it was
+// found in or at its current anchor
+func (g *Grid) holds(cell *GridCell, row, col int) bool {
+	slot := func(r, c int) *GridCell {
+		if r < 0 || r >= len(g.Cells) || c < 0 || c >= len(g.Cells[r]) {
+			return nil
+		}
+		return g.Cells[r][c]
+	}
+	return slot(row, col) == cell || slot(cell.Row, cell.Column) == cell
+}
+
+// RemoveAt takes the cell covering row and col out of the grid and returns
+// its content
+func (g *Grid) RemoveAt(row, col int) (Component, error) {
+	cell, err := g.itemAt(row, col)
+	if err != nil {
+		return nil, err
+	}
+	g.vacate(cell)
+	return cell.Content, nil
+}
+
+// MoveItem moves the cell covering fromRow and fromCol so that it is
+// anchored at toRow and toCol, keeping its span. The item may overlap its
+// own previous area. Grid.Move positions the grid itself, hence the name.
+func (g *Grid) MoveItem(fromRow, fromCol, toRow, toCol int) error {
+	cell, err := g.itemAt(fromRow, fromCol)
+	if err != nil {
+		return err
+	}
+	return g.relocate(cell, toRow, toCol, cell.RowSpan, cell.ColSpan)
+}
+
+// ResizeItem changes the span of the cell covering row and col, keeping its
+// anchor. Grid.Resize sizes the grid itself, hence the name.
+func (g *Grid) ResizeItem(row, col, rowSpan, colSpan int) error {
+	cell, err := g.itemAt(row, col)
+	if err != nil {
+		return err
+	}
+	return g.relocate(cell, cell.Row, cell.Column, rowSpan, colSpan)
+}
+
+// Replace puts component in the cell covering row and col, keeping its
+// placement, and returns the previous content
+func (g *Grid) Replace(row, col int, component Component) (Component, error) {
+	cell, err := g.itemAt(row, col)
+	if err != nil {
+		return nil, err
+	}
+	if component == nil {
+		return nil, &PlacementError{Row: row, Column: col, RowSpan: cell.RowSpan, ColSpan: cell.ColSpan, Err: ErrNilComponent}
+	}
+	previous := cell.Content
+	cell.Content = component
+	return previous, nil
+}
+
+// Swap exchanges the anchors of the cells covering the two coordinates.
+// Each item keeps its span; the grid is left unchanged when either no
+// longer fits.
+func (g *Grid) Swap(row1, col1, row2, col2 int) error {
+	a, err := g.itemAt(row1, col1)
+	if err != nil {
+		return err
+	}
+	b, err := g.itemAt(row2, col2)
+	if err != nil {
+		return err
+	}
+	if a == b {
+		return nil
+	}
+
+	oldA, oldB := *a, *b
+	g.vacate(a)
+	g.vacate(b)
+
+	restore := func(err error) error {
+		*a, *b = oldA, oldB
+		g.occupy(a)
+		g.occupy(b)
+		return err
+	}
+
+	if err := g.check(oldB.Row, oldB.Column, a.RowSpan, a.ColSpan); err != nil {
+		return restore(err)
+	}
+	a.Row, a.Column = oldB.Row, oldB.Column
+	g.occupy(a)
+
+	if err := g.check(oldA.Row, oldA.Column, b.RowSpan, b.ColSpan); err != nil {
+		g.vacate(a)
+		return restore(err)
+	}
+	b.Row, b.Column = oldA.Row, oldA.Column
+	g.occupy(b)
+	return nil
+}
+
+// itemAt returns the cell covering row and col
+func (g *Grid) itemAt(row, col int) (*GridCell, error) {
+	if row < 0 || row >= g.Rows || col < 0 || col >= g.Columns {
+		return nil, &PlacementError{Row: row, Column: col, Err: ErrOutOfRange}
+	}
+	cell := g.OccupantAt(row, col)
+	if cell == nil {
+		return nil, &PlacementError{Row: row, Column: col, Err: ErrEmptyCell}
+	}
+	return cell, nil
+}
+
+// relocate gives cell a new anchor and span, leaving it in place when the
+// new area is not valid
+func (g *Grid) relocate(cell *GridCell, row, col, rowSpan, colSpan int) error {
+	g.vacate(cell)
+	if err := g.check(row, col, rowSpan, colSpan); err != nil {
+		g.occupy(cell)
+		return err
+	}
+
+	cell.Row, cell.Column, cell.RowSpan, cell.ColSpan = row, col, rowSpan, colSpan
+	g.occupy(cell)
+	return nil
+}
+
+// vacate removes the cell from its anchor and frees the area it covers
+func (g *Grid) vacate(cell *GridCell) {
+	occupied := g.index()
+	if g.Cells[cell.Row][cell.Column] == cell {
+		g.Cells[cell.Row][cell.Column] = nil
+	}
+	// Spans are clipped as in index, so a bad span cannot stall the loop
+	for r := cell.Row; r < min(cell.Row+max(cell.RowSpan, 1), g.Rows); r++ {
+		for c := cell.Column; c < min(cell.Column+max(cell.ColSpan, 1), g.Columns); c++ {
+			if occupied[cellKey{r, c}] == cell {
+				delete(occupied, cellKey{r, c})
+			}
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package bussola
 
-import "encoding/json"
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
 
 // Dashboard represents a dashboard in Bussola.
 type Dashboard struct {
@@ -20,6 +25,30 @@ type Theme struct {
 	FontFamily string `json:"fontFamily"`
 }
 
+// ParseHexColor parses a theme color written as #RGB, #RRGGBB or #RRGGBBAA
+func ParseHexColor(s string) (color.NRGBA, error) {
+	hex, ok := strings.CutPrefix(strings.TrimSpace(s), "#")
+	if !ok {
+		return color.NRGBA{}, fmt.Errorf("bussola: color %q must start with #", s)
+	}
+
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("bussola: color %q must have 3, 6 or 8 hex digits", s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("bussola: color %q is not valid hex", s)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
 // NewDashboard creates a new Dashboard instance with default values.
 func NewDashboard(title, desc string) *Dashboard {
 	return &Dashboard{
@@ -47,20 +76,30 @@ func (d *Dashboard) SetTheme(theme *Theme) {
 
 // Render generates a JSON representation of the dashboard
 func (d *Dashboard) Render() map[string]any {
+	return d.RenderWith(nil)
+}
+
+// RenderWith is like Render but applies the visibility options
+func (d *Dashboard) RenderWith(opts *RenderOptions) map[string]any {
 	result := make(map[string]any)
 	result["title"] = d.Title
 	result["description"] = d.Description
 	result["theme"] = d.Theme
 
 	if d.Layout != nil {
-		result["layout"] = d.Layout.Render()
+		result["layout"] = d.Layout.RenderWith(opts)
 	}
 
 	return result
 }
 
-// GenerateJSON generates a JSON string representation of the dashboard
+// GenerateJSON generates a JSON string representation of the dashboard.
+// It returns an empty string when the dashboard cannot be encoded; use
+// MarshalJSON or WriteJSON to get the reason.
 func (d *Dashboard) GenerateJSON() string {
-	data, _ := json.Marshal(d.Render())
+	data, err := d.MarshalJSON()
+	if err != nil {
+		return ""
+	}
 	return string(data)
 }
@@ -0,0 +1,447 @@
+package bussola
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Limits applied by LoadDashboard and UnmarshalJSON so untrusted JSON
+// cannot make the decoder allocate huge grids. MaxDecodedCells bounds the
+// Rows x Columns of all the grids of one decode together, nested grids
+// included, and the columns of every breakpoint. MaxDecodedDepth bounds how
+// deeply grids nest, the root grid being at depth 1. Grids built in code
+// are not limited.
+const (
+	MaxDecodedCells = 10000
+	MaxDecodedDepth = 8
+)
+
+// decoder carries the limits shared by the grids of one decode
+type decoder struct {
+	cells int // cells still allowed
+}
+
+func newDecoder() *decoder {
+	return &decoder{cells: MaxDecodedCells}
+}
+
+// LoadDashboard parses the JSON produced by GenerateJSON back into a Dashboard
+func LoadDashboard(data []byte) (*Dashboard, error) {
+	d := &Dashboard{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// UnmarshalJSON decodes the representation emitted by Render
+func (d *Dashboard) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Title       string          `json:"title"`
+		Description string          `json:"description"`
+		Theme       *Theme          `json:"theme"`
+		Layout      json.RawMessage `json:"layout"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	d.Title = raw.Title
+	d.Description = raw.Description
+	d.Theme = raw.Theme
+	d.Layout = nil
+
+	if !isNull(raw.Layout) {
+		grid := &Grid{}
+		if err := newDecoder().grid(grid, raw.Layout, 1); err != nil {
+			return fmt.Errorf("layout: %w", err)
+		}
+		d.Layout = grid
+	}
+
+	return nil
+}
+
+// UnmarshalJSON decodes the representation emitted by Render and MarshalJSON
+func (g *Grid) UnmarshalJSON(data []byte) error {
+	return newDecoder().grid(g, data, 1)
+}
+
+// grid decodes a grid nested depth levels deep, charging its cells to the
+// decoder
+func (dec *decoder) grid(g *Grid, data []byte, depth int) error {
+	if depth > MaxDecodedDepth {
+		return fmt.Errorf("bussola: grids nest deeper than %d levels", MaxDecodedDepth)
+	}
+
+	var raw struct {
+		Title    string  `json:"title"`
+		Rows     int     `json:"rows"`
+		Columns  int     `json:"columns"`
+		Spacing  float64 `json:"spacing"`
+		Padding  float64 `json:"padding"`
+		AutoGrow bool    `json:"autoGrow"`
+		MaxRows  int     `json:"maxRows"`
+		Cells    []struct {
+			Row         int                  `json:"row"`
+			Column      int                  `json:"column"`
+			RowSpan     int                  `json:"rowSpan"`
+			ColSpan     int                  `json:"colSpan"`
+			Content     json.RawMessage      `json:"content"`
+			Breakpoints map[string]Placement `json:"breakpoints"`
+		} `json:"cells"`
+		Breakpoints []Breakpoint `json:"breakpoints"`
+		layoutState
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Rows < 0 || raw.Columns < 0 {
+		return fmt.Errorf("bussola: invalid grid size %dx%d", raw.Rows, raw.Columns)
+	}
+	// Rows are allocated even without columns, so each side is charged too
+	if raw.Rows > dec.cells || raw.Columns > dec.cells ||
+		raw.Columns > 0 && raw.Rows > dec.cells/raw.Columns {
+		return fmt.Errorf("bussola: grid size %dx%d exceeds the %d cells left of %d", raw.Rows, raw.Columns, dec.cells, MaxDecodedCells)
+	}
+	dec.cells -= max(raw.Rows*raw.Columns, raw.Rows, raw.Columns)
+	for i, bp := range raw.Breakpoints {
+		if bp.Columns > MaxDecodedCells {
+			return fmt.Errorf("breakpoints[%d]: bussola: %d columns exceed %d", i, bp.Columns, MaxDecodedCells)
+		}
+	}
+
+	*g = *NewGrid(raw.Title, raw.Rows, raw.Columns)
+	g.Spacing = raw.Spacing
+	g.Padding = raw.Padding
+	g.AutoGrow = raw.AutoGrow
+	g.MaxRows = raw.MaxRows
+	g.Breakpoints = raw.Breakpoints
+	raw.layoutState.apply(&g.BaseWidget)
+
+	// Cells go through the checks of PlaceItem, so spans running past the
+	// grid and overlapping or repeated cells are rejected
+	for i, c := range raw.Cells {
+		if err := g.check(c.Row, c.Column, c.RowSpan, c.ColSpan); err != nil {
+			return fmt.Errorf("cells[%d]: %w", i, err)
+		}
+
+		content, err := dec.component(c.Content, depth)
+		if err != nil {
+			return fmt.Errorf("cells[%d]: %w", i, err)
+		}
+
+		cell := &GridCell{
+			Row:     c.Row,
+			Column:  c.Column,
+			RowSpan: c.RowSpan,
+			ColSpan: c.ColSpan,
+			Content: content,
+		}
+		// Only overrides are kept; auto placements are derived again
+		for name, p := range c.Breakpoints {
+			if !p.Auto {
+				if bp, ok := g.Breakpoint(name); ok {
+					if err := g.checkOverride(bp, p); err != nil {
+						return fmt.Errorf("cells[%d].breakpoints[%q]: %w", i, name, err)
+					}
+				}
+				if cell.Overrides == nil {
+					cell.Overrides = map[string]Placement{}
+				}
+				cell.Overrides[name] = p
+			}
+		}
+		g.occupy(cell)
+	}
+
+	return nil
+}
+
+// component builds a component held by a grid at depth from its rendered
+// form, using the "type" discriminator. Grids carry no discriminator and
+// are detected by their "cells" key.
+func (dec *decoder) component(data json.RawMessage, depth int) (Component, error) {
+	if isNull(data) {
+		return nil, fmt.Errorf("bussola: missing component content")
+	}
+
+	var probe struct {
+		Type  string          `json:"type"`
+		Cells json.RawMessage `json:"cells"`
+		Error string          `json:"error"`
+		layoutState
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+
+	component, err := dec.typed(probe.Type, probe.Cells != nil, data, depth)
+	if err != nil {
+		return nil, err
+	}
+	if w, ok := component.(interface{ base() *BaseWidget }); ok {
+		if probe.Error != "" {
+			w.base().err = errors.New(probe.Error)
+		}
+		probe.layoutState.apply(w.base())
+	}
+	return component, nil
+}
+
+// layoutState holds the keys added by decorateLayout
+type layoutState struct {
+	Visible  *bool            `json:"visible"`
+	ShowWhen []VisibilityRule `json:"showWhen"`
+	Frame    *Rect            `json:"frame"`
+}
+
+func (s layoutState) apply(w *BaseWidget) {
+	w.hidden = s.Visible != nil && !*s.Visible
+	w.rules = s.ShowWhen
+	if s.Frame != nil {
+		w.Move(Position{X: s.Frame.X, Y: s.Frame.Y})
+		w.Resize(Size{Width: s.Frame.Width, Height: s.Frame.Height})
+	}
+}
+
+func (dec *decoder) typed(kind string, hasCells bool, data json.RawMessage, depth int) (Component, error) {
+	switch kind {
+	case "chart":
+		return decodeChart(data)
+	case "table":
+		return decodeTable(data)
+	case "indicator":
+		return decodeIndicator(data)
+	case "progressBar":
+		return decodeProgressBar(data)
+	case "filterBar":
+		return decodeFilterBar(data)
+	case "ranking":
+		return decodeRanking(data)
+	case "":
+		if hasCells {
+			grid := &Grid{}
+			if err := dec.grid(grid, data, depth+1); err != nil {
+				return nil, err
+			}
+			return grid, nil
+		}
+	}
+
+	return nil, fmt.Errorf("bussola: unknown component type %q", kind)
+}
+
+func decodeChart(data json.RawMessage) (*Chart, error) {
+	var raw struct {
+		Title     string    `json:"title"`
+		Subtitle  string    `json:"subtitle"`
+		ChartType ChartType `json:"chartType"`
+		Data      any       `json:"data"`
+		Options   any       `json:"options"`
+		Series    []Series  `json:"series"`
+		XAxis     *XAxis    `json:"xAxis"`
+		YAxis     *YAxis    `json:"yAxis"`
+		Stacked   bool      `json:"stacked"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	c := NewChart(raw.Title, raw.ChartType)
+	c.Subtitle = raw.Subtitle
+	c.Data = raw.Data
+	c.Options = raw.Options
+	c.Series = raw.Series
+	c.XAxis = raw.XAxis
+	c.YAxis = raw.YAxis
+	c.Stacked = raw.Stacked
+	return c, nil
+}
+
+func decodeTable(data json.RawMessage) (*Table, error) {
+	var raw struct {
+		Title       string           `json:"title"`
+		Headers     []string         `json:"headers"`
+		Columns     []Column         `json:"columns"`
+		Data        []map[string]any `json:"data"`
+		Sort        *TableSort       `json:"sort"`
+		PageSize    int              `json:"pageSize"`
+		CurrentPage int              `json:"currentPage"`
+		TotalRows   int              `json:"totalRows"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	t := NewTable(raw.Title, raw.Headers)
+	t.Columns = raw.Columns
+	t.Sort = raw.Sort
+	t.PageSize = raw.PageSize
+	t.CurrentPage = raw.CurrentPage
+
+	// A rendered table only holds its current page. When rows are missing,
+	// keep that page behind a source so the table renders the same again.
+	if raw.TotalRows > len(raw.Data) {
+		t.Source = &pageSnapshot{offset: t.pageRequest().Offset, rows: raw.Data, total: raw.TotalRows}
+		t.page, t.total = raw.Data, raw.TotalRows
+		return t, nil
+	}
+	t.Data = raw.Data
+	return t, nil
+}
+
+func decodeIndicator(data json.RawMessage) (*Indicator, error) {
+	var raw struct {
+		Title       string  `json:"title"`
+		Value       any     `json:"value"`
+		Target      any     `json:"target"`
+		Unit        string  `json:"unit"`
+		Trend       float64 `json:"trend"`
+		Description string  `json:"description"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	i := NewIndicator(raw.Title)
+	i.Value = raw.Value
+	i.Target = raw.Target
+	i.Unit = raw.Unit
+	i.Trend = raw.Trend
+	i.Description = raw.Description
+	return i, nil
+}
+
+func decodeProgressBar(data json.RawMessage) (*ProgressBar, error) {
+	var raw struct {
+		Title       string      `json:"title"`
+		Value       float64     `json:"value"`
+		MinValue    float64     `json:"minValue"`
+		MaxValue    float64     `json:"maxValue"`
+		ShowPercent bool        `json:"showPercent"`
+		Thresholds  []Threshold `json:"thresholds"`
+		Segments    []Segment   `json:"segments"`
+		Target      *float64    `json:"target"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	p := NewProgressBar(raw.Title)
+	p.Value = raw.Value
+	p.MinValue = raw.MinValue
+	p.MaxValue = raw.MaxValue
+	p.ShowPercent = raw.ShowPercent
+	p.Thresholds = raw.Thresholds
+	p.Segments = raw.Segments
+	p.Target = raw.Target
+	return p, nil
+}
+
+func decodeFilterBar(data json.RawMessage) (*FilterBar, error) {
+	var raw struct {
+		Title   string            `json:"title"`
+		Filters []json.RawMessage `json:"filters"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	f := NewFilterBar(raw.Title)
+	for i, item := range raw.Filters {
+		filter, err := decodeFilter(item)
+		if err != nil {
+			return nil, fmt.Errorf("filters[%d]: %w", i, err)
+		}
+		f.AddFilter(filter)
+	}
+	return f, nil
+}
+
+func decodeFilter(data json.RawMessage) (Filter, error) {
+	var raw struct {
+		Type        string   `json:"type"`
+		Label       string   `json:"label"`
+		Key         string   `json:"key"`
+		Options     []string `json:"options"`
+		Min         float64  `json:"min"`
+		Max         float64  `json:"max"`
+		Value       any      `json:"value"`
+		Placeholder string   `json:"placeholder"`
+		Default     any      `json:"default"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	switch raw.Type {
+	case "date":
+		return NewFilterDate(raw.Label, raw.Key), nil
+	case "select":
+		f := NewFilterSelect(raw.Label, raw.Key, raw.Options)
+		f.Default, _ = raw.Default.(string)
+		return f, nil
+	case "text":
+		return NewFilterText(raw.Label, raw.Key), nil
+	case "bool":
+		return NewFilterBool(raw.Label, raw.Key), nil
+	case "number":
+		return NewFilterNumber(raw.Label, raw.Key, raw.Min, raw.Max), nil
+	case "range":
+		return NewFilterRange(raw.Label, raw.Key, raw.Min, raw.Max), nil
+	case "checkbox":
+		f := NewFilterCheckbox(raw.Label, raw.Key, raw.Options)
+		f.Default, _ = toStrings(raw.Default)
+		return f, nil
+	case "radio":
+		f := NewFilterRadio(raw.Label, raw.Key, raw.Options)
+		f.Default, _ = raw.Default.(string)
+		return f, nil
+	case "multiSelect":
+		f := NewFilterMultiSelect(raw.Label, raw.Key, raw.Options)
+		f.Default, _ = toStrings(raw.Default)
+		return f, nil
+	case "slider":
+		value, ok := raw.Value.(float64)
+		if !ok && raw.Value != nil {
+			return nil, fmt.Errorf("bussola: slider value must be a number")
+		}
+		return NewFilterSlider(raw.Label, raw.Key, raw.Min, raw.Max, value), nil
+	case "toggle":
+		return NewFilterToggle(raw.Label, raw.Key), nil
+	case "search":
+		return NewFilterSearch(raw.Label, raw.Key, raw.Placeholder), nil
+	case "color":
+		value, ok := raw.Value.(string)
+		if !ok && raw.Value != nil {
+			return nil, fmt.Errorf("bussola: color value must be a string")
+		}
+		return NewFilterColor(raw.Label, raw.Key, value), nil
+	}
+
+	return nil, fmt.Errorf("bussola: unknown filter type %q", raw.Type)
+}
+
+func decodeRanking(data json.RawMessage) (*Ranking, error) {
+	var raw struct {
+		Title string        `json:"title"`
+		Order string        `json:"order"`
+		Items []RankingItem `json:"items"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	r := NewRanking(raw.Title)
+	r.Order = raw.Order
+	for _, item := range raw.Items {
+		r.AddItem(item)
+	}
+	return r, nil
+}
+
+func isNull(data json.RawMessage) bool {
+	return len(data) == 0 || string(data) == "null"
+}
@@ -0,0 +1,144 @@
+package bussola
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGridUnmarshalRejectsInvalidCells(t *testing.T) {
+	cell := func(row, col, rowSpan, colSpan string) string {
+		return `{"row":` + row + `,"column":` + col + `,"rowSpan":` + rowSpan + `,"colSpan":` + colSpan + `,"content":{"type":"indicator"}}`
+	}
+	tests := []struct {
+		name  string
+		cells string
+		want  error
+	}{
+		{"out of range", cell("2", "0", "1", "1"), ErrOutOfRange},
+		{"invalid span", cell("0", "0", "0", "1"), ErrInvalidSpan},
+		{"span overflow", cell("0", "0", "1000000000", "1"), ErrSpanOverflow},
+		{"overlap", cell("0", "0", "2", "1") + "," + cell("1", "0", "1", "1"), ErrOverlap},
+		{"repeated anchor", cell("0", "0", "1", "1") + "," + cell("0", "0", "1", "1"), ErrOverlap},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var g Grid
+			err := g.UnmarshalJSON([]byte(`{"rows":2,"columns":2,"cells":[` + tt.cells + `]}`))
+			var pe *PlacementError
+			if !errors.Is(err, tt.want) || !errors.As(err, &pe) {
+				t.Fatalf("UnmarshalJSON() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadDashboardRoundTrip(t *testing.T) {
+	want := fullDashboard(t).GenerateJSON()
+	if want == "" {
+		t.Fatal("GenerateJSON() returned no output")
+	}
+
+	data := want
+	for pass := 1; pass <= 2; pass++ {
+		d, err := LoadDashboard([]byte(data))
+		if err != nil {
+			t.Fatalf("pass %d: LoadDashboard() error = %v", pass, err)
+		}
+		if data = d.GenerateJSON(); data != want {
+			t.Fatalf("pass %d: JSON changed after decoding\n got: %s\nwant: %s", pass, data, want)
+		}
+	}
+}
+
+func TestGridUnmarshalRejectsHugeSizes(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"product", `{"rows":100000,"columns":100000,"cells":[]}`},
+		{"rows without columns", `{"rows":1000000000,"columns":0,"cells":[]}`},
+		{"columns without rows", `{"rows":0,"columns":1000000000,"cells":[]}`},
+		{"just past the limit", `{"rows":101,"columns":100,"cells":[]}`},
+		{"breakpoint columns", `{"rows":1,"columns":1,"cells":[],"breakpoints":[{"name":"sm","minWidth":0,"columns":1000000000}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var g Grid
+			if err := g.UnmarshalJSON([]byte(tt.json)); err == nil {
+				t.Fatalf("UnmarshalJSON() accepted %s", tt.json)
+			}
+		})
+	}
+
+	var g Grid
+	if err := g.UnmarshalJSON([]byte(`{"rows":100,"columns":100,"cells":[]}`)); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v for a grid at the limit", err)
+	}
+}
+
+func TestDecodeSharesCellBudget(t *testing.T) {
+	// A 100x100 grid whose every cell holds another 100x100 grid: each grid
+	// fits MaxDecodedCells on its own but not together
+	var inner strings.Builder
+	inner.WriteString(`{"rows":100,"columns":100,"cells":[]}`)
+	var cells strings.Builder
+	for i := range 100 * 100 {
+		if i > 0 {
+			cells.WriteByte(',')
+		}
+		fmt.Fprintf(&cells, `{"row":%d,"column":%d,"rowSpan":1,"colSpan":1,"content":%s}`, i/100, i%100, inner.String())
+	}
+	layout := `{"rows":100,"columns":100,"cells":[` + cells.String() + `]}`
+
+	if _, err := LoadDashboard([]byte(`{"layout":` + layout + `}`)); err == nil || !strings.Contains(err.Error(), "cells left") {
+		t.Fatalf("LoadDashboard() error = %v, want the cell budget exceeded", err)
+	}
+	var g Grid
+	if err := json.Unmarshal([]byte(layout), &g); err == nil {
+		t.Fatal("json.Unmarshal() of the nested grids succeeded")
+	}
+}
+
+func TestDecodeDepthLimit(t *testing.T) {
+	nest := func(depth int) string {
+		grid := `{"rows":1,"columns":1,"cells":[]}`
+		for range depth - 1 {
+			grid = `{"rows":1,"columns":1,"cells":[{"row":0,"column":0,"rowSpan":1,"colSpan":1,"content":` + grid + `}]}`
+		}
+		return `{"layout":` + grid + `}`
+	}
+
+	if _, err := LoadDashboard([]byte(nest(MaxDecodedDepth))); err != nil {
+		t.Fatalf("LoadDashboard() error = %v at depth %d", err, MaxDecodedDepth)
+	}
+	if _, err := LoadDashboard([]byte(nest(MaxDecodedDepth + 1))); err == nil || !strings.Contains(err.Error(), "nest deeper") {
+		t.Fatalf("LoadDashboard() error = %v, want the depth limit", err)
+	}
+}
+
+func TestGridJSONRoundTrip(t *testing.T) {
+	g := NewGrid("grid", 2, 2)
+	g.AddItem(NewIndicator("a"), 0, 0, 1, 2)
+	nested := NewGrid("nested", 1, 1)
+	nested.AddItem(NewProgressBar("b"), 0, 0, 1, 1)
+	g.AddItem(nested, 1, 0, 1, 1)
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if want, _ := json.Marshal(g.Render()); string(data) != string(want) {
+		t.Errorf("json.Marshal() = %s, want the Render form %s", data, want)
+	}
+
+	var back Grid
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if again, _ := json.Marshal(&back); string(again) != string(data) {
+		t.Errorf("round trip = %s, want %s", again, data)
+	}
+}
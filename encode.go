@@ -0,0 +1,110 @@
+package bussola
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// JSONOptions configures WriteJSON. A nil *JSONOptions writes the compact
+// output of GenerateJSON.
+type JSONOptions struct {
+	Prefix string // prefix of every indented line, as in json.Indent
+	Indent string // indentation of nested values; empty keeps the output compact
+
+	// Canonical sorts the keys of every object, including typed values such
+	// as the theme and chart series, so the output is stable across versions
+	Canonical bool
+
+	// Render sets how hidden components are written, as in RenderWith
+	Render *RenderOptions
+}
+
+// JSONError reports a value that cannot be encoded, such as a NaN or a
+// channel stored in Chart.Data, with the path of the widget holding it
+type JSONError struct {
+	Path string
+	Err  error
+}
+
+func (e *JSONError) Error() string {
+	if e.Path == "" {
+		return "bussola: cannot encode dashboard: " + e.Err.Error()
+	}
+	return "bussola: cannot encode " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *JSONError) Unwrap() error { return e.Err }
+
+// MarshalJSON encodes the representation emitted by Render. Failures are
+// reported as a *JSONError.
+func (d *Dashboard) MarshalJSON() ([]byte, error) {
+	return d.marshal(nil)
+}
+
+// MarshalJSON encodes the representation emitted by Render, the form read
+// back by UnmarshalJSON. Failures are reported as a *JSONError.
+func (g *Grid) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e := newEncoder(&buf, nil)
+	g.encodeJSON(e)
+	e.flush()
+	if e.err != nil {
+		return nil, e.err
+	}
+	return buf.Bytes(), nil
+}
+
+func (d *Dashboard) marshal(opts *RenderOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.encode(&buf, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteJSON writes the dashboard JSON to w, indented or canonical as set in
+// opts. Failures to encode are reported as a *JSONError. The compact output
+// is streamed, so part of it may have been written when an error occurs.
+func (d *Dashboard) WriteJSON(w io.Writer, opts *JSONOptions) error {
+	if opts == nil {
+		return d.encode(w, nil)
+	}
+	if !opts.Canonical && opts.Prefix == "" && opts.Indent == "" {
+		return d.encode(w, opts.Render)
+	}
+
+	data, err := d.marshal(opts.Render)
+	if err != nil {
+		return err
+	}
+
+	if opts.Canonical {
+		if data, err = canonicalJSON(data); err != nil {
+			return err
+		}
+	}
+	if opts.Prefix != "" || opts.Indent != "" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, opts.Prefix, opts.Indent); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// canonicalJSON re-encodes data through generic maps, which encoding/json
+// writes with sorted keys. Numbers keep their original text.
+func canonicalJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,118 @@
+package bussola
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func encodeDashboard() *Dashboard {
+	chart := NewChart("revenue", ChartLine)
+	chart.AddSeries("sales", "#1976D2", 1, 2.5)
+	g := NewGrid("grid", 1, 2)
+	g.AddItem(NewIndicator("users"), 0, 0, 1, 1)
+	g.AddItem(chart, 0, 1, 1, 1)
+	d := NewDashboard("dashboard", "")
+	d.SetLayout(g)
+	return d
+}
+
+func TestWriteJSONIndent(t *testing.T) {
+	d := encodeDashboard()
+	compact, err := d.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := d.WriteJSON(&buf, &JSONOptions{Prefix: "> ", Indent: "\t"}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var want bytes.Buffer
+	if err := json.Indent(&want, compact, "> ", "\t"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != want.String() {
+		t.Errorf("WriteJSON() =\n%s\nwant\n%s", buf.String(), want.String())
+	}
+	if !strings.Contains(buf.String(), "\n> \t\"layout\": {") {
+		t.Errorf("WriteJSON() = %.200s, want prefixed and indented lines", buf.String())
+	}
+
+	buf.Reset()
+	if err := d.WriteJSON(&buf, nil); err != nil || buf.String() != string(compact) {
+		t.Errorf("WriteJSON(nil) = %s, %v, want the compact output", buf.String(), err)
+	}
+}
+
+func TestWriteJSONCanonical(t *testing.T) {
+	d := encodeDashboard()
+	var buf bytes.Buffer
+	if err := d.WriteJSON(&buf, &JSONOptions{Canonical: true}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	out := buf.String()
+
+	// Typed values such as the theme and chart series are sorted as well
+	for _, want := range []string{
+		`"theme":{"background":"#FFFFFF","fontFamily":"Roboto, sans-serif","primary":"#1976D2","secondary":"#424242","textColor":"#212121"}`,
+		`"series":[{"color":"#1976D2","data":[1,2.5],"name":"sales"}]`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteJSON() = %s, want it to contain %s", out, want)
+		}
+	}
+
+	compact, _ := d.MarshalJSON()
+	var a, b any
+	if json.Unmarshal(compact, &a) != nil || json.Unmarshal(buf.Bytes(), &b) != nil || !jsonEqual(a, b) {
+		t.Error("canonical output differs from the compact output beyond key order")
+	}
+}
+
+func jsonEqual(a, b any) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return bytes.Equal(x, y)
+}
+
+func TestJSONErrorPath(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(d *Dashboard)
+		path string
+	}{
+		{"chart data", func(d *Dashboard) {
+			d.Layout.Cells[0][1].Content.(*Chart).Data = []any{1.0, make(chan int)}
+		}, "layout.cells[0][1].content.data"},
+		{"indicator value", func(d *Dashboard) {
+			d.Layout.Cells[0][0].Content.(*Indicator).Value = math.Inf(1)
+		}, "layout.cells[0][0].content.value"},
+		{"grid spacing", func(d *Dashboard) {
+			d.Layout.Spacing = math.NaN()
+		}, "layout.spacing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := encodeDashboard()
+			tt.set(d)
+
+			for _, opts := range []*JSONOptions{nil, {Indent: "  "}, {Canonical: true}} {
+				var je *JSONError
+				err := d.WriteJSON(&bytes.Buffer{}, opts)
+				if !errors.As(err, &je) || je.Path != tt.path {
+					t.Fatalf("WriteJSON(%+v) error = %v, want a *JSONError at %s", opts, err, tt.path)
+				}
+			}
+			if _, err := json.Marshal(d); err == nil || !strings.Contains(err.Error(), tt.path) {
+				t.Errorf("json.Marshal() error = %v, want the path %s", err, tt.path)
+			}
+			if got := d.GenerateJSON(); got != "" {
+				t.Errorf("GenerateJSON() = %.100s, want no output", got)
+			}
+		})
+	}
+}
@@ -42,18 +42,24 @@ func main() {
 	nestedGrid.AddItem(bussola.NewProgressBar("Total Conversion"), 1, 0, 1, 1)
 
 	// Create a chart
-	revenueChart := bussola.NewChart("Revenue Over Time", "line")
-	revenueChart.Data = []float64{1200, 1900, 3000, 5000, 4100, 4500}
-	revenueChart.Options = map[string]any{
-		"xAxis": []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun"},
-		"color": "#1976D2",
-	}
+	revenueChart := bussola.NewChart("Revenue Over Time", bussola.ChartLine)
+	revenueChart.SetCategories("Jan", "Feb", "Mar", "Apr", "May", "Jun")
+	revenueChart.AddSeries("Revenue", "#1976D2", 1200, 1900, 3000, 5000, 4100, 4500)
+	revenueChart.AddSeries("Costs", "#FFC107", 800, 1100, 1700, 2600, 2500, 2700)
+	revenueChart.YAxis = &bussola.YAxis{Unit: "R$"}
 
 	userTable := bussola.NewTable("Recent Users", []string{"ID", "Name", "Last Access", "Status"})
 	userTable.Data = []map[string]any{
 		{"id": 1, "name": "John Doe", "lastAccess": "2025-06-26", "status": "Active"},
 		{"id": 2, "name": "Jane Smith", "lastAccess": "2025-06-25", "status": "Inactive"},
 	}
+	userTable.AddColumn(bussola.NewColumn("id", "ID", bussola.ColumnNumber))
+	userTable.AddColumn(bussola.NewColumn("name", "Name", bussola.ColumnString))
+	lastAccess := bussola.NewColumn("lastAccess", "Last Access", bussola.ColumnDate)
+	lastAccess.Format = "02/01/2006"
+	userTable.AddColumn(lastAccess)
+	userTable.AddColumn(bussola.NewColumn("status", "Status", bussola.ColumnString))
+	userTable.SortBy("lastAccess", true)
 
 	filterBar := bussola.NewFilterBar("Filtros Gerais")
 	filterBar.AddFilter(bussola.NewFilterDate("Periodo", "period"))
@@ -64,7 +70,8 @@ func main() {
 	mainGrid.AddItem(filterBar, 0, 0, 1, 3)
 
 	// Adicionando indicadores automaticamente no grid de indicadores
-	indicators := bussola.NewGrid("Indicators", 2, 4)
+	indicators := bussola.NewGrid("Indicators", 1, 4)
+	indicators.AutoGrow = true
 	indicators.AddNext(sales)
 	indicators.AddNext(users)
 	indicators.AddNext(sales)
@@ -86,6 +93,13 @@ func main() {
 	ranking.SetOrder("desc")
 	mainGrid.AddItem(ranking, 3, 2, 1, 1)
 
+	// Stack the widgets on phones and use two columns on tablets, keeping
+	// the chart next to the progress bars
+	mainGrid.AddBreakpoint("xs", 0, 1)
+	mainGrid.AddBreakpoint("sm", 576, 2)
+	mainGrid.AddBreakpoint("lg", 992, 3)
+	mainGrid.SetOverride(2, 1, "sm", bussola.Placement{Row: 2, Column: 1, RowSpan: 1, ColSpan: 1})
+
 	// Set the main grid as the dashboard layout
 	dashboard.SetLayout(mainGrid)
 
@@ -98,6 +112,11 @@ func main() {
 		FontFamily: "Inter, sans-serif",
 	})
 
+	// Resolve the position and size of every widget for a desktop viewport
+	if _, err := dashboard.ComputeLayout(bussola.Size{Width: 1200}); err != nil {
+		log.Fatalf("Error computing layout: %v", err)
+	}
+
 	fmt.Printf("Dashboard JSON:\n%v\n", dashboard.GenerateJSON())
 
 	if err := preview.GeneratePreview(dashboard, "dashboard.jpg"); err != nil {
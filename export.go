@@ -0,0 +1,247 @@
+package bussola
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultExportPageSize is the number of rows an export loads per request
+// from a TableSource
+const DefaultExportPageSize = 500
+
+// ExportFormat is a file format a table can be exported to
+type ExportFormat string
+
+const (
+	ExportCSV       ExportFormat = "csv"
+	ExportTSV       ExportFormat = "tsv"
+	ExportJSONLines ExportFormat = "jsonl"
+)
+
+// ContentType returns the MIME type of the format
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportCSV:
+		return "text/csv; charset=utf-8"
+	case ExportTSV:
+		return "text/tab-separated-values; charset=utf-8"
+	case ExportJSONLines:
+		return "application/jsonl"
+	}
+	return "application/octet-stream"
+}
+
+// ExportOptions configures a table export. A nil *ExportOptions writes a
+// comma separated file with a header row.
+type ExportOptions struct {
+	Comma    rune // CSV field delimiter, ',' when zero
+	NoHeader bool // omit the header row of CSV and TSV files
+
+	// PageSize is the number of rows loaded per request from Source,
+	// DefaultExportPageSize when zero
+	PageSize int
+}
+
+// Export writes every row of the table to w in the given format. Rows come
+// in the order set by Sort, with the visible columns in Columns order (or
+// Headers order for tables without Columns).
+// Tables with a Source are read page by page, so part of the output may have
+// been written when an error occurs. Tables that are not Complete return
+// ErrPartialTable before writing anything.
+func (t *Table) Export(ctx context.Context, w io.Writer, format ExportFormat, opts *ExportOptions) error {
+	switch format {
+	case ExportCSV:
+		return t.WriteCSV(ctx, w, opts)
+	case ExportTSV:
+		return t.WriteTSV(ctx, w, opts)
+	case ExportJSONLines:
+		return t.WriteJSONLines(ctx, w, opts)
+	}
+	return fmt.Errorf("bussola: unknown export format %q", format)
+}
+
+// WriteCSV writes the table as comma separated values
+func (t *Table) WriteCSV(ctx context.Context, w io.Writer, opts *ExportOptions) error {
+	if !t.Complete() {
+		return ErrPartialTable
+	}
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+
+	cw := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		cw.Comma = opts.Comma
+	}
+
+	columns := t.VisibleColumns()
+	record := make([]string, len(columns))
+	if !opts.NoHeader {
+		for i, col := range columns {
+			record[i] = col.Label
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	err := t.eachPage(ctx, opts.PageSize, func(rows []map[string]any) error {
+		for _, row := range rows {
+			for i, col := range columns {
+				record[i] = exportText(col.Value(row))
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteTSV writes the table as tab separated values. Fields are never
+// quoted; tabs and line breaks inside them become spaces.
+func (t *Table) WriteTSV(ctx context.Context, w io.Writer, opts *ExportOptions) error {
+	if !t.Complete() {
+		return ErrPartialTable
+	}
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+
+	bw := bufio.NewWriter(w)
+	columns := t.VisibleColumns()
+	writeLine := func(field func(col Column) string) {
+		for i, col := range columns {
+			if i > 0 {
+				bw.WriteByte('\t')
+			}
+			bw.WriteString(tsvReplacer.Replace(field(col)))
+		}
+		bw.WriteByte('\n')
+	}
+
+	if !opts.NoHeader {
+		writeLine(func(col Column) string { return col.Label })
+	}
+
+	err := t.eachPage(ctx, opts.PageSize, func(rows []map[string]any) error {
+		for _, row := range rows {
+			writeLine(func(col Column) string { return exportText(col.Value(row)) })
+		}
+		return bw.Flush()
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+var tsvReplacer = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+// WriteJSONLines writes one JSON object per row, holding the visible
+// columns in the order of VisibleColumns
+func (t *Table) WriteJSONLines(ctx context.Context, w io.Writer, opts *ExportOptions) error {
+	if !t.Complete() {
+		return ErrPartialTable
+	}
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+
+	bw := bufio.NewWriter(w)
+	columns := t.VisibleColumns()
+	line := []byte{}
+
+	err := t.eachPage(ctx, opts.PageSize, func(rows []map[string]any) error {
+		for _, row := range rows {
+			line = append(line[:0], '{')
+			for i, col := range columns {
+				if i > 0 {
+					line = append(line, ',')
+				}
+				value, err := json.Marshal(col.Value(row))
+				if err != nil {
+					return fmt.Errorf("bussola: cannot export column %q: %w", col.Key, err)
+				}
+				line = appendString(line, col.Key)
+				line = append(line, ':')
+				line = append(line, value...)
+			}
+			line = append(line, '}', '\n')
+			bw.Write(line)
+		}
+		return bw.Flush()
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// eachPage calls fn with the rows of the table in the order set by Sort,
+// loading them from Source one page at a time
+func (t *Table) eachPage(ctx context.Context, pageSize int, fn func(rows []map[string]any) error) error {
+	if t.Source == nil {
+		return fn(t.SortedData())
+	}
+
+	if pageSize <= 0 {
+		pageSize = DefaultExportPageSize
+	}
+	for offset := 0; ; {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rows, total, err := t.Source.LoadPage(ctx, PageRequest{Offset: offset, Limit: pageSize, Sort: t.Sort})
+		if err != nil {
+			return err
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+		offset += len(rows)
+		if len(rows) == 0 || offset >= total {
+			return nil
+		}
+	}
+}
+
+// exportText formats a value for CSV and TSV files. Unlike FormatValue it
+// keeps numbers and dates machine readable.
+func exportText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case fmt.Stringer:
+		return v.String()
+	}
+	if n, ok := toFloat(v); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	if data, err := json.Marshal(v); err == nil {
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,137 @@
+package bussola
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// pagedTable returns a table reading n rows from a Source, with its first
+// page loaded
+func pagedTable(t *testing.T, n int) *Table {
+	t.Helper()
+	rows := make([]map[string]any, n)
+	for i := range rows {
+		rows[i] = map[string]any{"id": i + 1}
+	}
+
+	table := NewTable("Paged", []string{"ID"})
+	table.Source = TableSourceFunc(func(ctx context.Context, req PageRequest) ([]map[string]any, int, error) {
+		end := len(rows)
+		if req.Limit > 0 {
+			end = min(req.Offset+req.Limit, len(rows))
+		}
+		return rows[min(req.Offset, end):end], len(rows), nil
+	})
+	if err := table.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+func TestExportDecodedPagedTable(t *testing.T) {
+	data, err := json.Marshal(pagedTable(t, 25).Render())
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := decodeTable(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Complete() {
+		t.Fatal("Complete() = true for a decoded paged table")
+	}
+
+	for _, format := range []ExportFormat{ExportCSV, ExportTSV, ExportJSONLines} {
+		var buf bytes.Buffer
+		err := table.Export(context.Background(), &buf, format, nil)
+		if !errors.Is(err, ErrPartialTable) {
+			t.Errorf("Export(%s) error = %v, want ErrPartialTable", format, err)
+		}
+		if buf.Len() > 0 {
+			t.Errorf("Export(%s) wrote %q before failing", format, buf.String())
+		}
+	}
+}
+
+func TestExportPagesThroughSource(t *testing.T) {
+	table := pagedTable(t, 25)
+	var buf bytes.Buffer
+	if err := table.Export(context.Background(), &buf, ExportCSV, &ExportOptions{PageSize: 7}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 26 {
+		t.Errorf("Export() wrote %d lines, want the header and 25 rows", lines)
+	}
+}
+
+// exportTable returns a table whose Columns order differs from its Headers,
+// with a hidden column and values that need quoting
+func exportTable() *Table {
+	table := NewTable("Export", []string{"Name", "Amount"})
+	secret := NewColumn("secret", "Secret", ColumnString)
+	secret.Hidden = true
+	table.Columns = []Column{
+		NewColumn("amount", "Amount", ColumnNumber),
+		NewColumn("name", "Name, full", ColumnString),
+		secret,
+		NewColumn("active", "Active", ColumnBool),
+	}
+	table.Data = []map[string]any{
+		{"name": `Ann "A", Jr`, "amount": 1.5, "secret": "x", "active": true},
+		{"name": "Bob\tand\nCo", "amount": 10, "active": false},
+		{"name": "Cy;D"},
+	}
+	return table
+}
+
+func TestExportBytes(t *testing.T) {
+	tests := []struct {
+		name   string
+		format ExportFormat
+		opts   *ExportOptions
+		want   string
+	}{
+		{"csv", ExportCSV, nil, "" +
+			"Amount,\"Name, full\",Active\n" +
+			"1.5,\"Ann \"\"A\"\", Jr\",true\n" +
+			"10,\"Bob\tand\nCo\",false\n" +
+			",Cy;D,\n"},
+		{"csv comma", ExportCSV, &ExportOptions{Comma: ';'}, "" +
+			"Amount;Name, full;Active\n" +
+			"1.5;\"Ann \"\"A\"\", Jr\";true\n" +
+			"10;\"Bob\tand\nCo\";false\n" +
+			";\"Cy;D\";\n"},
+		{"csv no header", ExportCSV, &ExportOptions{NoHeader: true}, "" +
+			"1.5,\"Ann \"\"A\"\", Jr\",true\n" +
+			"10,\"Bob\tand\nCo\",false\n" +
+			",Cy;D,\n"},
+		{"tsv", ExportTSV, nil, "" +
+			"Amount\tName, full\tActive\n" +
+			"1.5\tAnn \"A\", Jr\ttrue\n" +
+			"10\tBob and Co\tfalse\n" +
+			"\tCy;D\t\n"},
+		{"tsv no header", ExportTSV, &ExportOptions{NoHeader: true}, "" +
+			"1.5\tAnn \"A\", Jr\ttrue\n" +
+			"10\tBob and Co\tfalse\n" +
+			"\tCy;D\t\n"},
+		{"jsonl", ExportJSONLines, nil, "" +
+			`{"amount":1.5,"name":"Ann \"A\", Jr","active":true}` + "\n" +
+			`{"amount":10,"name":"Bob\tand\nCo","active":false}` + "\n" +
+			`{"amount":null,"name":"Cy;D","active":null}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := exportTable().Export(context.Background(), &buf, tt.format, tt.opts); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Export() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
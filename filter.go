@@ -0,0 +1,753 @@
+package bussola
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLayout is the layout of dates in filter values
+const DateLayout = "2006-01-02"
+
+// DateRange is the value of a date filter. A single date gives a range
+// where From and To are equal.
+type DateRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// NumberRange is the value of a range filter
+type NumberRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// FilterValues holds the values selected by the user, keyed by filter key.
+// Each value has the type of its filter: DateRange for date, NumberRange for
+// range, float64 for number and slider, bool for bool and toggle, []string
+// for checkbox and multiSelect and string for the others.
+type FilterValues map[string]any
+
+// String returns the value of a text-like filter
+func (v FilterValues) String(key string) string {
+	s, _ := v[key].(string)
+	return s
+}
+
+// Strings returns the values of a checkbox or multiSelect filter
+func (v FilterValues) Strings(key string) []string {
+	s, _ := v[key].([]string)
+	return s
+}
+
+// Number returns the value of a number or slider filter
+func (v FilterValues) Number(key string) (float64, bool) {
+	n, ok := v[key].(float64)
+	return n, ok
+}
+
+// Bool returns the value of a bool or toggle filter
+func (v FilterValues) Bool(key string) bool {
+	b, _ := v[key].(bool)
+	return b
+}
+
+// Dates returns the value of a date filter
+func (v FilterValues) Dates(key string) (DateRange, bool) {
+	r, ok := v[key].(DateRange)
+	return r, ok
+}
+
+// Range returns the value of a range filter
+func (v FilterValues) Range(key string) (NumberRange, bool) {
+	r, ok := v[key].(NumberRange)
+	return r, ok
+}
+
+// Only returns the values of the given keys
+func (v FilterValues) Only(keys ...string) FilterValues {
+	out := FilterValues{}
+	for _, key := range keys {
+		if value, ok := v[key]; ok {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// valueFilter is implemented by the filters that accept a user value
+type valueFilter interface {
+	Filter
+	Validate() error
+	ValidateValue(v any) error
+	filterKey() string
+	parse(raw []string) (any, error)
+}
+
+// Validate checks every filter of the bar and reports filters sharing a key
+func (f *FilterBar) Validate() error {
+	v := &validator{}
+	keys := map[string]int{}
+	for i, flt := range f.Filters {
+		field := joinPath("filters", indexPath(i))
+		if flt == nil {
+			v.add(field, "is nil")
+			continue
+		}
+
+		vf, ok := flt.(valueFilter)
+		if !ok {
+			continue
+		}
+		v.merge(field, vf.Validate())
+
+		key := vf.filterKey()
+		if key == "" {
+			continue
+		}
+		if j, ok := keys[key]; ok {
+			v.add(field+".key", "%q duplicates filters[%d]", key, j)
+			continue
+		}
+		keys[key] = i
+	}
+	return v.err()
+}
+
+// ParseValues reads the values of the bar's filters from a query string.
+// Multiple values repeat the key; ranges take two values or a single
+// "from,to" value. Keys without a filter are ignored.
+func (f *FilterBar) ParseValues(values url.Values) (FilterValues, error) {
+	return parseFilters(f.Filters, values)
+}
+
+// ParseValueMap reads the values of the bar's filters from a decoded JSON
+// object. Ranges are given as {"from", "to"} or {"min", "max"} objects.
+// Keys without a filter are ignored.
+func (f *FilterBar) ParseValueMap(values map[string]any) (FilterValues, error) {
+	return parseFilterMap(f.Filters, values)
+}
+
+// ParseFilters reads the values of every filter in the dashboard from a
+// query string, see FilterBar.ParseValues
+func (d *Dashboard) ParseFilters(values url.Values) (FilterValues, error) {
+	return parseFilters(d.filters(), values)
+}
+
+// ParseFilterMap reads the values of every filter in the dashboard from a
+// decoded JSON object, see FilterBar.ParseValueMap
+func (d *Dashboard) ParseFilterMap(values map[string]any) (FilterValues, error) {
+	return parseFilterMap(d.filters(), values)
+}
+
+func (d *Dashboard) filters() []Filter {
+	if d.Layout == nil {
+		return nil
+	}
+
+	filters := []Filter{}
+	seen := map[*FilterBar]bool{}
+	d.Layout.walk("layout", func(path string, cell *GridCell) {
+		if bar, ok := cell.Content.(*FilterBar); ok && bar != nil && !seen[bar] {
+			seen[bar] = true
+			filters = append(filters, bar.Filters...)
+		}
+	})
+	return filters
+}
+
+func parseFilters(filters []Filter, values url.Values) (FilterValues, error) {
+	out := FilterValues{}
+	v := &validator{}
+	for _, flt := range filters {
+		vf, ok := flt.(valueFilter)
+		if !ok {
+			continue
+		}
+		key := vf.filterKey()
+		raw := values[key]
+		if len(raw) == 0 || len(raw) == 1 && raw[0] == "" {
+			continue
+		}
+
+		value, err := vf.parse(raw)
+		if err != nil {
+			v.merge(key, err)
+			continue
+		}
+		out[key] = value
+	}
+	return out, v.err()
+}
+
+func parseFilterMap(filters []Filter, values map[string]any) (FilterValues, error) {
+	known := map[string]bool{}
+	for _, flt := range filters {
+		if vf, ok := flt.(valueFilter); ok {
+			known[vf.filterKey()] = true
+		}
+	}
+
+	query := url.Values{}
+	v := &validator{}
+	for key, value := range values {
+		if !known[key] {
+			continue
+		}
+		raw, err := rawValues(value)
+		if err != nil {
+			v.add(key, "%v", err)
+			continue
+		}
+		query[key] = raw
+	}
+
+	out, err := parseFilters(filters, query)
+	v.merge("", err)
+	return out, v.err()
+}
+
+// rawValues turns a decoded JSON value into the strings of a query string
+func rawValues(value any) ([]string, error) {
+	switch val := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{val}, nil
+	case bool:
+		return []string{strconv.FormatBool(val)}, nil
+	case float64:
+		return []string{strconv.FormatFloat(val, 'g', -1, 64)}, nil
+	case int:
+		return []string{strconv.Itoa(val)}, nil
+	case json.Number:
+		return []string{val.String()}, nil
+	case []string:
+		return val, nil
+	case []any:
+		out := []string{}
+		for _, item := range val {
+			raw, err := rawValues(item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, raw...)
+		}
+		return out, nil
+	case map[string]any:
+		for _, bounds := range [][2]string{{"from", "to"}, {"min", "max"}} {
+			lo, hasLo := val[bounds[0]]
+			hi, hasHi := val[bounds[1]]
+			if !hasLo && !hasHi {
+				continue
+			}
+			return rawValues([]any{lo, hi})
+		}
+		return nil, fmt.Errorf("object must have from/to or min/max")
+	}
+	return nil, fmt.Errorf("unsupported value %T", value)
+}
+
+func single(raw []string) (string, error) {
+	if len(raw) != 1 {
+		return "", fmt.Errorf("expects a single value, got %d", len(raw))
+	}
+	return raw[0], nil
+}
+
+func pair(raw []string) (string, string, error) {
+	if len(raw) == 1 {
+		raw = strings.SplitN(raw[0], ",", 2)
+	}
+	switch len(raw) {
+	case 1:
+		return raw[0], raw[0], nil
+	case 2:
+		return raw[0], raw[1], nil
+	}
+	return "", "", fmt.Errorf("expects at most two values, got %d", len(raw))
+}
+
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(DateLayout, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date", s)
+}
+
+func parseNumber(s string) (float64, error) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	return n, nil
+}
+
+func parseBool(raw []string) (bool, error) {
+	s, err := single(raw)
+	if err != nil {
+		return false, err
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("%q is not a boolean", s)
+	}
+	return b, nil
+}
+
+func invalidType(want string, value any) error {
+	v := &validator{}
+	v.add("", "expects %s, got %T", want, value)
+	return v.err()
+}
+
+func validateKey(v *validator, key string) {
+	if strings.TrimSpace(key) == "" {
+		v.add("key", "is required")
+	}
+}
+
+func validateBounds(v *validator, min, max float64) {
+	finite := validateFinite(v, "min", min)
+	if validateFinite(v, "max", max) && finite && min > max {
+		v.add("max", "%g is less than min %g", max, min)
+	}
+}
+
+func validateBound(v *validator, field string, n, min, max float64) {
+	if validateFinite(v, field, n) && (n < min || n > max) {
+		v.add(field, "%g is outside [%g, %g]", n, min, max)
+	}
+}
+
+// validateFinite reports NaN and infinite values, which every comparison
+// with a bound would otherwise let through
+func validateFinite(v *validator, field string, n float64) bool {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		v.add(field, "must be a finite number, got %g", n)
+		return false
+	}
+	return true
+}
+
+func validateOptions(v *validator, options []string) {
+	if len(options) == 0 {
+		v.add("options", "must not be empty")
+		return
+	}
+	seen := map[string]int{}
+	for i, option := range options {
+		if j, ok := seen[option]; ok {
+			v.add(joinPath("options", indexPath(i)), "duplicates options[%d]", j)
+			continue
+		}
+		seen[option] = i
+	}
+}
+
+func validateChoice(v *validator, field string, options []string, s string) {
+	if !slices.Contains(options, s) {
+		v.add(field, "%q is not one of the options", s)
+	}
+}
+
+func validateChoices(v *validator, field string, options, values []string) {
+	for i, s := range values {
+		validateChoice(v, joinPath(field, indexPath(i)), options, s)
+	}
+}
+
+func (f *FilterDate) filterKey() string { return f.Key }
+
+// Validate checks the filter definition
+func (f *FilterDate) Validate() error {
+	v := &validator{}
+	validateKey(v, f.Key)
+	return v.err()
+}
+
+// ValidateValue checks a DateRange selected for the filter
+func (f *FilterDate) ValidateValue(value any) error {
+	r, ok := value.(DateRange)
+	if !ok {
+		return invalidType("a date range", value)
+	}
+	v := &validator{}
+	if r.To.Before(r.From) {
+		v.add("to", "is before from")
+	}
+	return v.err()
+}
+
+func (f *FilterDate) parse(raw []string) (any, error) {
+	from, to, err := pair(raw)
+	if err != nil {
+		return nil, err
+	}
+	r := DateRange{}
+	if r.From, err = parseDate(from); err != nil {
+		return nil, err
+	}
+	if r.To, err = parseDate(to); err != nil {
+		return nil, err
+	}
+	return r, f.ValidateValue(r)
+}
+
+func (f *FilterSelect) filterKey() string { return f.Key }
+
+// Validate checks the filter definition
+func (f *FilterSelect) Validate() error {
+	v := &validator{}
+	validateKey(v, f.Key)
+	validateOptions(v, f.Options)
+	if f.Default != "" && len(f.Options) > 0 {
+		validateChoice(v, "default", f.Options, f.Default)
+	}
+	return v.err()
+}
+
+// ValidateValue checks an option selected for the filter
+func (f *FilterSelect) ValidateValue(value any) error {
+	s, ok := value.(string)
+	if !ok {
+		return invalidType("a string", value)
+	}
+	v := &validator{}
+	validateChoice(v, "", f.Options, s)
+	return v.err()
+}
+
+func (f *FilterSelect) parse(raw []string) (any, error) {
+	s, err := single(raw)
+	if err != nil {
+		return nil, err
+	}
+	return s, f.ValidateValue(s)
+}
+
+func (f *FilterText) filterKey() string { return f.Key }
+
+// Validate checks the filter definition
+func (f *FilterText) Validate() error {
+	v := &validator{}
+	validateKey(v, f.Key)
+	return v.err()
+}
+
+// ValidateValue checks a text typed for the filter
+func (f *FilterText) ValidateValue(value any) error {
+	if _, ok := value.(string); !ok {
+		return invalidType("a string", value)
+	}
+	return nil
+}
+
+func (f *FilterText) parse(raw []string) (any, error) {
+	return single(raw)
+}
+
+func (f *FilterBool) filterKey() string { return f.Key }
+
+// Validate checks the filter definition
+func (f *FilterBool) Validate() error {
+	v := &validator{}
+	validateKey(v, f.Key)
+	return v.err()
+}
+
+// ValidateValue checks a boolean selected for the filter
+func (f *FilterBool) ValidateValue(value any) error {
+	if _, ok := value.(bool); !ok {
+		return invalidType("a boolean", value)
+	}
+	return nil
+}
+
+func (f *FilterBool) parse(raw []string) (any, error) {
+	return parseBool(raw)
+}
+
+func (f *FilterNumber) filterKey() string { return f.Key }
+
+// Validate checks the filter definition
+func (f *FilterNumber) Validate() error {
+	v := &validator{}
+	validateKey(v, f.Key)
+	validateBounds(v, f.Min, f.Max)
+	return v.err()
+}
+
+// ValidateValue checks a number typed for the filter
+func (f *FilterNumber) ValidateValue(value any) error {
+	n, ok := toFloat(value)
+	if !ok {
+		return invalidType("a number", value)
+	}
+	v := &validator{}
+	validateBound(v, "", n, f.Min, f.Max)
+	return v.err()
+}
+
+func (f *FilterNumber) parse(raw []string) (any, error) {
+	s, err := single(raw)
+	if err != nil {
+		return nil, err
+	}
+	n, err := parseNumber(s)
+	if err != nil {
+		return nil, err
+	}
+	return n, f.ValidateValue(n)
+}
+
+func (f *FilterRange) filterKey() string { return f.Key }
+
+// Validate checks the filter definition
+func (f *FilterRange) Validate() error {
+	v := &validator{}
+	validateKey(v, f.Key)
+	validateBounds(v, f.Min, f.Max)
+	return v.err()
+}
+
+// ValidateValue checks a NumberRange selected for the filter
+func (f *FilterRange) ValidateValue(value any) error {
+	r, ok := value.(NumberRange)
+	if !ok {
+		return invalidType("a number range", value)
+	}
+	v := &validator{}
+	if r.Min > r.Max {
+		v.add("max", "%g is less than min %g", r.Max, r.Min)
+	}
+	validateBound(v, "min", r.Min, f.Min, f.Max)
+	validateBound(v, "max", r.Max, f.Min, f.Max)
+	return v.err()
+}
+
+func (f *FilterRange) parse(raw []string) (any, error) {
+	lo, hi, err := pair(raw)
+	if err != nil {
+		return nil, err
+	}
+	r := NumberRange{}
+	if r.Min, err = parseNumber(lo); err != nil {
+		return nil, err
+	}
+	if r.Max, err = parseNumber(hi); err != nil {
+		return nil, err
+	}
+	return r, f.ValidateValue(r)
+}
+
+func (f *FilterCheckbox) filterKey() string { return f.Key }
+
+// Validate checks the filter definition
+func (f *FilterCheckbox) Validate() error {
+	v := &validator{}
+	validateKey(v, f.Key)
+	validateOptions(v, f.Options)
+	if len(f.Options) > 0 {
+		validateChoices(v, "default", f.Options, f.Default)
+	}
+	return v.err()
+}
+
+// ValidateValue checks the options checked for the filter
+func (f *FilterCheckbox) ValidateValue(value any) error {
+	values, ok := value.([]string)
+	if !ok {
+		return invalidType("a list of strings", value)
+	}
+	v := &validator{}
+	validateChoices(v, "", f.Options, values)
+	return v.err()
+}
+
+func (f *FilterCheckbox) parse(raw []string) (any, error) {
+	values := slices.Clone(raw)
+	return values, f.ValidateValue(values)
+}
+
+func (f *FilterRadio) filterKey() string { return f.Key }
+
+// Validate checks the filter definition
+func (f *FilterRadio) Validate() error {
+	v := &validator{}
+	validateKey(v, f.Key)
+	validateOptions(v, f.Options)
+	if f.Default != "" && len(f.Options) > 0 {
+		validateChoice(v, "default", f.Options, f.Default)
+	}
+	return v.err()
+}
+
+// ValidateValue checks an option selected for the filter
+func (f *FilterRadio) ValidateValue(value any) error {
+	s, ok := value.(string)
+	if !ok {
+		return invalidType("a string", value)
+	}
+	v := &validator{}
+	validateChoice(v, "", f.Options, s)
+	return v.err()
+}
+
+func (f *FilterRadio) parse(raw []string) (any, error) {
+	s, err := single(raw)
+	if err != nil {
+		return nil, err
+	}
+	return s, f.ValidateValue(s)
+}
+
+func (f *FilterMultiSelect) filterKey() string { return f.Key }
+
+// Validate checks the filter definition
+func (f *FilterMultiSelect) Validate() error {
+	v := &validator{}
+	validateKey(v, f.Key)
+	validateOptions(v, f.Options)
+	if len(f.Options) > 0 {
+		validateChoices(v, "default", f.Options, f.Default)
+	}
+	return v.err()
+}
+
+// ValidateValue checks the options selected for the filter
+func (f *FilterMultiSelect) ValidateValue(value any) error {
+	values, ok := value.([]string)
+	if !ok {
+		return invalidType("a list of strings", value)
+	}
+	v := &validator{}
+	validateChoices(v, "", f.Options, values)
+	return v.err()
+}
+
+func (f *FilterMultiSelect) parse(raw []string) (any, error) {
+	values := slices.Clone(raw)
+	return values, f.ValidateValue(values)
+}
+
+func (f *FilterSlider) filterKey() string { return f.Key }
+
+// Validate checks the filter definition
+func (f *FilterSlider) Validate() error {
+	v := &validator{}
+	validateKey(v, f.Key)
+	validateBounds(v, f.Min, f.Max)
+	if f.Min <= f.Max {
+		validateBound(v, "value", f.Value, f.Min, f.Max)
+	}
+	return v.err()
+}
+
+// ValidateValue checks a position selected on the slider
+func (f *FilterSlider) ValidateValue(value any) error {
+	n, ok := toFloat(value)
+	if !ok {
+		return invalidType("a number", value)
+	}
+	v := &validator{}
+	validateBound(v, "", n, f.Min, f.Max)
+	return v.err()
+}
+
+func (f *FilterSlider) parse(raw []string) (any, error) {
+	s, err := single(raw)
+	if err != nil {
+		return nil, err
+	}
+	n, err := parseNumber(s)
+	if err != nil {
+		return nil, err
+	}
+	return n, f.ValidateValue(n)
+}
+
+func (f *FilterToggle) filterKey() string { return f.Key }
+
+// Validate checks the filter definition
+func (f *FilterToggle) Validate() error {
+	v := &validator{}
+	validateKey(v, f.Key)
+	return v.err()
+}
+
+// ValidateValue checks a state selected for the toggle
+func (f *FilterToggle) ValidateValue(value any) error {
+	if _, ok := value.(bool); !ok {
+		return invalidType("a boolean", value)
+	}
+	return nil
+}
+
+func (f *FilterToggle) parse(raw []string) (any, error) {
+	return parseBool(raw)
+}
+
+func (f *FilterSearch) filterKey() string { return f.Key }
+
+// Validate checks the filter definition
+func (f *FilterSearch) Validate() error {
+	v := &validator{}
+	validateKey(v, f.Key)
+	return v.err()
+}
+
+// ValidateValue checks a search term typed for the filter
+func (f *FilterSearch) ValidateValue(value any) error {
+	if _, ok := value.(string); !ok {
+		return invalidType("a string", value)
+	}
+	return nil
+}
+
+func (f *FilterSearch) parse(raw []string) (any, error) {
+	return single(raw)
+}
+
+func (f *FilterColor) filterKey() string { return f.Key }
+
+// Validate checks the filter definition
+func (f *FilterColor) Validate() error {
+	v := &validator{}
+	validateKey(v, f.Key)
+	if f.Value != "" {
+		if _, err := ParseHexColor(f.Value); err != nil {
+			v.add("value", "%v", err)
+		}
+	}
+	return v.err()
+}
+
+// ValidateValue checks a color picked for the filter
+func (f *FilterColor) ValidateValue(value any) error {
+	s, ok := value.(string)
+	if !ok {
+		return invalidType("a string", value)
+	}
+	v := &validator{}
+	if _, err := ParseHexColor(s); err != nil {
+		v.add("", "%v", err)
+	}
+	return v.err()
+}
+
+func (f *FilterColor) parse(raw []string) (any, error) {
+	s, err := single(raw)
+	if err != nil {
+		return nil, err
+	}
+	return s, f.ValidateValue(s)
+}
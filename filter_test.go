@@ -0,0 +1,262 @@
+package bussola
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseValuesRejectsNonFiniteNumbers(t *testing.T) {
+	bar := NewFilterBar("filters")
+	bar.AddFilter(NewFilterNumber("Number", "n", 0, 10))
+	bar.AddFilter(NewFilterRange("Range", "r", 0, 10))
+	bar.AddFilter(NewFilterSlider("Slider", "s", 0, 10, 5))
+
+	for _, query := range []string{"n=NaN", "n=Inf", "n=-Inf", "r=NaN,NaN", "r=0,Inf", "s=NaN"} {
+		values, _ := url.ParseQuery(query)
+		got, err := bar.ParseValues(values)
+		if err == nil {
+			t.Errorf("ParseValues(%q) = %v, want an error", query, got)
+		}
+	}
+}
+
+func TestValidateRejectsNonFiniteNumbers(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	filters := []interface{ Validate() error }{
+		NewFilterNumber("Number", "n", nan, 10),
+		NewFilterNumber("Number", "n", 0, inf),
+		NewFilterRange("Range", "r", nan, nan),
+		NewFilterSlider("Slider", "s", 0, 10, nan),
+		NewFilterSlider("Slider", "s", nan, 10, 5),
+	}
+	for _, f := range filters {
+		if err := f.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded, want an error", f)
+		}
+	}
+
+	values := []struct {
+		filter interface{ ValidateValue(any) error }
+		value  any
+	}{
+		{NewFilterNumber("Number", "n", 0, 10), nan},
+		{NewFilterSlider("Slider", "s", 0, 10, 5), inf},
+		{NewFilterRange("Range", "r", 0, 10), NumberRange{Min: nan, Max: 5}},
+	}
+	for _, tt := range values {
+		if err := tt.filter.ValidateValue(tt.value); err == nil {
+			t.Errorf("ValidateValue(%v) succeeded, want an error", tt.value)
+		}
+	}
+}
+
+// fieldsOf returns the fields of the problems reported by err
+func fieldsOf(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("error = %v, want a *ValidationError", err)
+	}
+	fields := make([]string, len(ve.Errors))
+	for i, fe := range ve.Errors {
+		fields[i] = fe.Field
+	}
+	return fields
+}
+
+func parseBar() *FilterBar {
+	bar := NewFilterBar("filters")
+	bar.AddFilter(NewFilterDate("Period", "period"))
+	bar.AddFilter(NewFilterNumber("Amount", "amount", 0, 10))
+	bar.AddFilter(NewFilterRange("Price", "price", 0, 100))
+	bar.AddFilter(NewFilterSelect("Status", "status", []string{"open", "closed"}))
+	bar.AddFilter(NewFilterCheckbox("Tags", "tags", []string{"a", "b", "c"}))
+	return bar
+}
+
+func TestParseValues(t *testing.T) {
+	jan1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jan31 := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query  string
+		want   FilterValues
+		fields []string
+	}{
+		{"period=2024-01-01", FilterValues{"period": DateRange{From: jan1, To: jan1}}, nil},
+		{"period=2024-01-01,2024-01-31", FilterValues{"period": DateRange{From: jan1, To: jan31}}, nil},
+		{"period=2024-01-01&period=2024-01-31", FilterValues{"period": DateRange{From: jan1, To: jan31}}, nil},
+		{"period=2024-01-31,2024-01-01", FilterValues{}, []string{"period.to"}},
+		{"period=yesterday", FilterValues{}, []string{"period"}},
+		{"amount=0&price=0,100", FilterValues{"amount": 0.0, "price": NumberRange{Min: 0, Max: 100}}, nil},
+		{"amount=10.5", FilterValues{}, []string{"amount"}},
+		{"price=-1,50", FilterValues{}, []string{"price.min"}},
+		{"price=50,101", FilterValues{}, []string{"price.max"}},
+		{"price=60,40", FilterValues{}, []string{"price.max"}},
+		{"status=open&tags=a&tags=c", FilterValues{"status": "open", "tags": []string{"a", "c"}}, nil},
+		{"status=pending", FilterValues{}, []string{"status"}},
+		{"status=open&status=closed", FilterValues{}, []string{"status"}},
+		{"tags=a&tags=z", FilterValues{}, []string{"tags[1]"}},
+		{"region=north&status=", FilterValues{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			got, err := parseBar().ParseValues(values)
+			if fields := fieldsOf(t, err); !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("ParseValues() error fields = %q, want %q (%v)", fields, tt.fields, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseValueMap(t *testing.T) {
+	jan1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jan31 := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	got, err := parseBar().ParseValueMap(map[string]any{
+		"period":  map[string]any{"from": "2024-01-01", "to": "2024-01-31"},
+		"price":   map[string]any{"min": 5.0, "max": 50.0},
+		"amount":  3.0,
+		"status":  "closed",
+		"tags":    []any{"b", "c"},
+		"unknown": map[string]any{"nested": true},
+	})
+	if err != nil {
+		t.Fatalf("ParseValueMap() error = %v", err)
+	}
+	want := FilterValues{
+		"period": DateRange{From: jan1, To: jan31},
+		"price":  NumberRange{Min: 5, Max: 50},
+		"amount": 3.0,
+		"status": "closed",
+		"tags":   []string{"b", "c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseValueMap() = %v, want %v", got, want)
+	}
+
+	_, err = parseBar().ParseValueMap(map[string]any{
+		"period": map[string]any{"start": "2024-01-01"},
+		"price":  map[string]any{"min": 50.0, "max": 5.0},
+		"tags":   []any{"a", 1.0},
+	})
+	fields := fieldsOf(t, err)
+	wantFields := map[string]bool{"period": true, "price.max": true, "tags[1]": true}
+	if len(fields) != len(wantFields) {
+		t.Fatalf("ParseValueMap() error fields = %q, want %v", fields, wantFields)
+	}
+	for _, field := range fields {
+		if !wantFields[field] {
+			t.Errorf("ParseValueMap() error fields = %q, want %v", fields, wantFields)
+		}
+	}
+}
+
+func TestDashboardParseFilters(t *testing.T) {
+	top := NewFilterBar("top")
+	top.AddFilter(NewFilterSelect("Status", "status", []string{"open", "closed"}))
+	nested := NewFilterBar("nested")
+	nested.AddFilter(NewFilterNumber("Amount", "amount", 0, 10))
+
+	inner := NewGrid("inner", 1, 1)
+	inner.AddItem(nested, 0, 0, 1, 1)
+	grid := NewGrid("grid", 1, 2)
+	grid.AddItem(top, 0, 0, 1, 1)
+	grid.AddItem(inner, 0, 1, 1, 1)
+	d := NewDashboard("dashboard", "")
+	d.SetLayout(grid)
+
+	values, _ := url.ParseQuery("status=open&amount=4&other=1")
+	got, err := d.ParseFilters(values)
+	if err != nil {
+		t.Fatalf("ParseFilters() error = %v", err)
+	}
+	if want := (FilterValues{"status": "open", "amount": 4.0}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseFilters() = %v, want %v", got, want)
+	}
+
+	values, _ = url.ParseQuery("amount=40")
+	if _, err := d.ParseFilters(values); !reflect.DeepEqual(fieldsOf(t, err), []string{"amount"}) {
+		t.Errorf("ParseFilters() error = %v, want one on amount", err)
+	}
+}
+
+func TestFilterBarValidate(t *testing.T) {
+	withDefault := NewFilterSelect("Status", "status", []string{"open", "closed"})
+	withDefault.Default = "pending"
+	checkbox := NewFilterCheckbox("Tags", "tags", []string{"a", "b", "a"})
+	checkbox.Default = []string{"b", "z"}
+
+	bar := NewFilterBar("filters")
+	bar.AddFilter(NewFilterDate("Period", "period"))
+	bar.AddFilter(NewFilterText("Period", "period"))
+	bar.AddFilter(NewFilterRadio("Region", "", nil))
+	bar.AddFilter(withDefault)
+	bar.AddFilter(checkbox)
+	bar.AddFilter(NewFilterNumber("Amount", "amount", 10, 0))
+	bar.AddFilter(NewFilterSlider("Zoom", "zoom", 0, 10, 11))
+	bar.AddFilter(NewFilterColor("Color", "color", "red"))
+	bar.AddFilter(nil)
+
+	want := []string{
+		"filters[1].key",
+		"filters[2].key",
+		"filters[2].options",
+		"filters[3].default",
+		"filters[4].options[2]",
+		"filters[4].default[1]",
+		"filters[5].max",
+		"filters[6].value",
+		"filters[7].value",
+		"filters[8]",
+	}
+	if got := fieldsOf(t, bar.Validate()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() fields = %q\nwant %q", got, want)
+	}
+
+	valid := parseBar()
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestFilterValidateValue(t *testing.T) {
+	jan1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		filter interface{ ValidateValue(any) error }
+		value  any
+		fields []string
+	}{
+		{"date in order", NewFilterDate("Period", "period"), DateRange{From: jan1, To: jan1.AddDate(0, 1, 0)}, nil},
+		{"date reversed", NewFilterDate("Period", "period"), DateRange{From: jan1, To: jan1.AddDate(0, 0, -1)}, []string{"to"}},
+		{"date wrong type", NewFilterDate("Period", "period"), "2024-01-01", []string{""}},
+		{"slider at max", NewFilterSlider("Zoom", "zoom", 0, 10, 5), 10.0, nil},
+		{"slider below min", NewFilterSlider("Zoom", "zoom", 0, 10, 5), -1, []string{""}},
+		{"slider above max", NewFilterSlider("Zoom", "zoom", 0, 10, 5), 10.5, []string{""}},
+		{"range reversed", NewFilterRange("Price", "price", 0, 100), NumberRange{Min: 60, Max: 40}, []string{"max"}},
+		{"range out of bounds", NewFilterRange("Price", "price", 0, 100), NumberRange{Min: -5, Max: 200}, []string{"min", "max"}},
+		{"select option", NewFilterSelect("Status", "status", []string{"open"}), "open", nil},
+		{"select unknown", NewFilterSelect("Status", "status", []string{"open"}), "closed", []string{""}},
+		{"multi select unknown", NewFilterMultiSelect("Tags", "tags", []string{"a", "b"}), []string{"a", "c"}, []string{"[1]"}},
+		{"color", NewFilterColor("Color", "color", ""), "#zzzzzz", []string{""}},
+		{"toggle", NewFilterToggle("Active", "active"), "true", []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.ValidateValue(tt.value)
+			if got := fieldsOf(t, err); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("ValidateValue(%v) fields = %q, want %q (%v)", tt.value, got, tt.fields, err)
+			}
+		})
+	}
+}
@@ -3,3 +3,5 @@ module github.com/isaqueveras/bussola
 go 1.23.9
 
 require golang.org/x/image v0.13.0
+
+require golang.org/x/text v0.13.0 // indirect
@@ -1,5 +1,10 @@
 package bussola
 
+import (
+	"errors"
+	"fmt"
+)
+
 // Grid represents a grid layout for organizing widgets in a dashboard.
 type Grid struct {
 	BaseWidget
@@ -9,8 +14,23 @@ type Grid struct {
 	Cells   [][]*GridCell `json:"cells"`
 	Spacing float64       `json:"spacing"`
 	Padding float64       `json:"padding"`
+
+	// Breakpoints rearrange the grid for narrower or wider viewports
+	Breakpoints []Breakpoint `json:"breakpoints"`
+
+	// AutoGrow lets PlaceNext and AddNext append rows when no free slot
+	// fits. A positive MaxRows caps the rows the grid may grow to.
+	AutoGrow bool `json:"autoGrow"`
+	MaxRows  int  `json:"maxRows"`
+
+	// occupied maps every coordinate covered by a cell span to that cell.
+	// It is built from Cells on first use and kept up to date by the grid
+	// methods; Reindex drops it after Cells is written directly.
+	occupied map[cellKey]*GridCell
 }
 
+type cellKey struct{ row, col int }
+
 // GridCell represents a cell in the grid
 type GridCell struct {
 	Row     int       `json:"row"`
@@ -18,6 +38,9 @@ type GridCell struct {
 	RowSpan int       `json:"rowSpan"`
 	ColSpan int       `json:"colSpan"`
 	Content Component `json:"content"`
+
+	// Overrides places the cell at named breakpoints instead of the reflow
+	Overrides map[string]Placement `json:"overrides"`
 }
 
 // NewGrid creates a new grid with the specified number of rows and columns
@@ -37,24 +60,105 @@ func NewGrid(title string, rows, columns int) *Grid {
 	}
 }
 
-// AddItem adds a component to the grid at the specified position
+// Placement errors reported by PlaceItem, PlaceNext and the editing methods.
+// Use errors.Is to tell them apart and errors.As with *PlacementError to get
+// the offending cell.
+var (
+	ErrNilComponent = errors.New("bussola: component is nil")
+	ErrOutOfRange   = errors.New("bussola: position out of range")
+	ErrInvalidSpan  = errors.New("bussola: span must be positive")
+	ErrSpanOverflow = errors.New("bussola: span runs past the grid")
+	ErrOverlap      = errors.New("bussola: cell overlaps an existing item")
+	ErrNoSpace      = errors.New("bussola: no free slot fits the span")
+	ErrEmptyCell    = errors.New("bussola: cell is empty")
+	ErrMaxRows      = errors.New("bussola: grid cannot grow past its maximum rows")
+)
+
+// PlacementError describes a component that could not be placed in a grid
+type PlacementError struct {
+	Row      int
+	Column   int
+	RowSpan  int
+	ColSpan  int
+	Conflict *GridCell // existing cell hit by an overlap, if any
+	Err      error
+}
+
+func (e *PlacementError) Error() string {
+	switch e.Err {
+	case ErrNoSpace, ErrMaxRows:
+		return fmt.Sprintf("%v %dx%d", e.Err, e.RowSpan, e.ColSpan)
+	case ErrEmptyCell:
+		return fmt.Sprintf("%v at (%d, %d)", e.Err, e.Row, e.Column)
+	}
+	return fmt.Sprintf("%v at (%d, %d) span %dx%d", e.Err, e.Row, e.Column, e.RowSpan, e.ColSpan)
+}
+
+func (e *PlacementError) Unwrap() error { return e.Err }
+
+// AddItem adds a component to the grid at the specified position.
+// Invalid placements are ignored; use PlaceItem to get the reason.
 func (g *Grid) AddItem(component Component, row, col, rowSpan, colSpan int) {
-	if row < 0 || row >= g.Rows || col < 0 || col >= g.Columns {
-		return
+	_ = g.PlaceItem(component, row, col, rowSpan, colSpan)
+}
+
+// AddNext adds a component to the first free slot that fits the optional
+// rowSpan and colSpan values. It does nothing when no slot fits; use
+// PlaceNext to get an error instead.
+func (g *Grid) AddNext(component Component, values ...int) {
+	_ = g.PlaceNext(component, values...)
+}
+
+// PlaceItem adds a component to the grid at the specified position and
+// returns a *PlacementError when the position or span is not valid
+func (g *Grid) PlaceItem(component Component, row, col, rowSpan, colSpan int) error {
+	if component == nil {
+		return &PlacementError{Row: row, Column: col, RowSpan: rowSpan, ColSpan: colSpan, Err: ErrNilComponent}
+	}
+	if err := g.check(row, col, rowSpan, colSpan); err != nil {
+		return err
 	}
 
-	cell := &GridCell{
+	g.occupy(&GridCell{
 		Row:     row,
 		Column:  col,
 		RowSpan: rowSpan,
 		ColSpan: colSpan,
 		Content: component,
+	})
+
+	return nil
+}
+
+// check returns a *PlacementError when an item cannot cover the area
+func (g *Grid) check(row, col, rowSpan, colSpan int) error {
+	fail := func(err error, conflict *GridCell) error {
+		return &PlacementError{Row: row, Column: col, RowSpan: rowSpan, ColSpan: colSpan, Conflict: conflict, Err: err}
 	}
 
-	g.Cells[row][col] = cell
+	if row < 0 || row >= g.Rows || col < 0 || col >= g.Columns {
+		return fail(ErrOutOfRange, nil)
+	}
+	// Literal grids may hold fewer cells than Rows and Columns declare
+	if row >= len(g.Cells) || col >= len(g.Cells[row]) {
+		return fail(ErrOutOfRange, nil)
+	}
+	if rowSpan <= 0 || colSpan <= 0 {
+		return fail(ErrInvalidSpan, nil)
+	}
+	if row+rowSpan > g.Rows || col+colSpan > g.Columns {
+		return fail(ErrSpanOverflow, nil)
+	}
+	if other := g.overlapping(row, col, rowSpan, colSpan); other != nil {
+		return fail(ErrOverlap, other)
+	}
+	return nil
 }
 
-func (g *Grid) AddNext(component Component, values ...int) {
+// PlaceNext adds a component to the first free slot, in reading order, that
+// fits the optional rowSpan and colSpan values. Grids with AutoGrow append
+// the rows needed when no slot fits.
+func (g *Grid) PlaceNext(component Component, values ...int) error {
 	rowSpan, colSpan := 1, 1
 	if len(values) > 0 {
 		rowSpan = values[0]
@@ -64,23 +168,139 @@ func (g *Grid) AddNext(component Component, values ...int) {
 		colSpan = values[1]
 	}
 
-	for row := 0; row < g.Rows; row++ {
-		for col := 0; col < g.Columns; col++ {
-			occupied := false
-			for r := row; r < row+rowSpan && r < g.Rows; r++ {
-				for c := col; c < col+colSpan && c < g.Columns; c++ {
-					if g.Cells[r][c] != nil {
-						occupied = true
-						break
-					}
-				}
-				if occupied {
-					break
+	if component == nil {
+		return &PlacementError{RowSpan: rowSpan, ColSpan: colSpan, Err: ErrNilComponent}
+	}
+	if rowSpan <= 0 || colSpan <= 0 {
+		return &PlacementError{RowSpan: rowSpan, ColSpan: colSpan, Err: ErrInvalidSpan}
+	}
+
+	for row := 0; row+rowSpan <= g.Rows; row++ {
+		for col := 0; col+colSpan <= g.Columns; col++ {
+			if g.IsFree(row, col, rowSpan, colSpan) {
+				return g.PlaceItem(component, row, col, rowSpan, colSpan)
+			}
+		}
+	}
+
+	if g.AutoGrow && colSpan <= g.Columns {
+		return g.growInto(component, rowSpan, colSpan)
+	}
+	return &PlacementError{RowSpan: rowSpan, ColSpan: colSpan, Err: ErrNoSpace}
+}
+
+// growInto places a component in the first slot that fits once rows are
+// appended below the grid, appending as few rows as possible
+func (g *Grid) growInto(component Component, rowSpan, colSpan int) error {
+	for row := max(g.Rows-rowSpan+1, 0); row <= g.Rows; row++ {
+		for col := 0; col+colSpan <= g.Columns; col++ {
+			if g.overlapping(row, col, rowSpan, colSpan) != nil {
+				continue
+			}
+			if g.MaxRows > 0 && row+rowSpan > g.MaxRows {
+				return &PlacementError{RowSpan: rowSpan, ColSpan: colSpan, Err: ErrMaxRows}
+			}
+			g.grow(row + rowSpan)
+			return g.PlaceItem(component, row, col, rowSpan, colSpan)
+		}
+	}
+	return &PlacementError{RowSpan: rowSpan, ColSpan: colSpan, Err: ErrNoSpace}
+}
+
+// grow appends empty rows until the grid has the given number of rows
+func (g *Grid) grow(rows int) {
+	for g.Rows < rows {
+		g.Cells = append(g.Cells, make([]*GridCell, g.Columns))
+		g.Rows++
+	}
+}
+
+// IsFree reports whether the area starting at row and col and spanning
+// rowSpan x colSpan lies inside the grid and is not covered by any cell
+func (g *Grid) IsFree(row, col, rowSpan, colSpan int) bool {
+	if row < 0 || col < 0 || rowSpan <= 0 || colSpan <= 0 {
+		return false
+	}
+	if row+rowSpan > g.Rows || col+colSpan > g.Columns {
+		return false
+	}
+	return g.overlapping(row, col, rowSpan, colSpan) == nil
+}
+
+// OccupantAt returns the cell whose span covers the given coordinate, or nil
+func (g *Grid) OccupantAt(row, col int) *GridCell {
+	return g.index()[cellKey{row, col}]
+}
+
+// overlapping returns the first cell covering any coordinate of the area
+func (g *Grid) overlapping(row, col, rowSpan, colSpan int) *GridCell {
+	occupied := g.index()
+	for r := row; r < row+rowSpan; r++ {
+		for c := col; c < col+colSpan; c++ {
+			if cell := occupied[cellKey{r, c}]; cell != nil {
+				return cell
+			}
+		}
+	}
+	return nil
+}
+
+// occupy stores the cell at its anchor and marks the area it covers
+func (g *Grid) occupy(cell *GridCell) {
+	occupied := g.index()
+	g.Cells[cell.Row][cell.Column] = cell
+	for r := cell.Row; r < min(cell.Row+max(cell.RowSpan, 1), g.Rows); r++ {
+		for c := cell.Column; c < min(cell.Column+max(cell.ColSpan, 1), g.Columns); c++ {
+			occupied[cellKey{r, c}] = cell
+		}
+	}
+}
+
+// Reindex drops the occupancy index so it is built again from Cells. Call
+// it after writing Cells directly; IsFree, OccupantAt and PlaceItem
+// otherwise answer from the index built on first use.
+func (g *Grid) Reindex() {
+	g.occupied = nil
+}
+
+// index returns the occupancy map, building it from Cells the first time
+// it is needed (e.g. for grids built as literals) or after Reindex. Spans
+// are clipped to the grid so a bad span cannot grow the index unbounded.
+func (g *Grid) index() map[cellKey]*GridCell {
+	if g.occupied != nil {
+		return g.occupied
+	}
+
+	g.occupied = make(map[cellKey]*GridCell)
+	for i := range g.Cells {
+		for j := range g.Cells[i] {
+			cell := g.Cells[i][j]
+			if cell == nil {
+				continue
+			}
+			for r := cell.Row; r < min(cell.Row+max(cell.RowSpan, 1), g.Rows); r++ {
+				for c := cell.Column; c < min(cell.Column+max(cell.ColSpan, 1), g.Columns); c++ {
+					g.occupied[cellKey{r, c}] = cell
 				}
 			}
-			if !occupied {
-				g.AddItem(component, row, col, rowSpan, colSpan)
-				return
+		}
+	}
+	return g.occupied
+}
+
+// walk calls fn for every cell of the grid and its nested grids, in reading
+// order, with a path such as "layout.cells[1][0]" built from prefix
+func (g *Grid) walk(prefix string, fn func(path string, cell *GridCell)) {
+	for i := range g.Cells {
+		for j := range g.Cells[i] {
+			cell := g.Cells[i][j]
+			if cell == nil {
+				continue
+			}
+			path := fmt.Sprintf("%s.cells[%d][%d]", prefix, i, j)
+			fn(path, cell)
+			if nested, ok := cell.Content.(*Grid); ok && nested != nil {
+				nested.walk(path+".content", fn)
 			}
 		}
 	}
@@ -88,23 +308,52 @@ func (g *Grid) AddNext(component Component, values ...int) {
 
 // Render generates a JSON representation of the grid
 func (g *Grid) Render() map[string]any {
+	return g.RenderWith(nil)
+}
+
+// RenderWith is like Render but applies the visibility options
+func (g *Grid) RenderWith(opts *RenderOptions) map[string]any {
 	result := make(map[string]any)
 	result["title"] = g.Title
 	result["rows"] = g.Rows
 	result["columns"] = g.Columns
 	result["spacing"] = g.Spacing
 	result["padding"] = g.Padding
+	if g.AutoGrow {
+		result["autoGrow"] = true
+	}
+	if g.MaxRows > 0 {
+		result["maxRows"] = g.MaxRows
+	}
+
+	arrangements := g.arrangements()
+	placements := placementsOf(arrangements)
+	if len(arrangements) > 0 {
+		breakpoints := make([]map[string]any, len(arrangements))
+		for i, bp := range g.Breakpoints {
+			breakpoints[i] = map[string]any{
+				"name":     bp.Name,
+				"minWidth": bp.MinWidth,
+				"columns":  bp.Columns,
+				"rows":     arrangements[i].Rows,
+			}
+		}
+		result["breakpoints"] = breakpoints
+	}
 
 	cells := []map[string]any{}
 	for i := range g.Cells {
 		for j := range g.Cells[i] {
-			if cell := g.Cells[i][j]; cell != nil {
+			if cell := g.Cells[i][j]; cell != nil && !opts.omit(cell.Content) {
 				cellData := map[string]any{
 					"row":     cell.Row,
 					"column":  cell.Column,
 					"rowSpan": cell.RowSpan,
 					"colSpan": cell.ColSpan,
-					"content": cell.Content.Render(),
+					"content": RenderComponent(cell.Content, opts),
+				}
+				if len(arrangements) > 0 {
+					cellData["breakpoints"] = placements[cell]
 				}
 				cells = append(cells, cellData)
 			}
@@ -112,5 +361,5 @@ func (g *Grid) Render() map[string]any {
 	}
 	result["cells"] = cells
 
-	return result
+	return decorateLayout(result, g, opts)
 }
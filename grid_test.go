@@ -0,0 +1,373 @@
+package bussola
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGridReindexAfterDirectWrite(t *testing.T) {
+	g := NewGrid("grid", 2, 2)
+	if !g.IsFree(0, 0, 1, 1) {
+		t.Fatal("IsFree(0, 0) = false on an empty grid")
+	}
+
+	cell := &GridCell{Row: 0, Column: 0, RowSpan: 2, ColSpan: 1, Content: NewIndicator("a")}
+	g.Cells[0][0] = cell
+	g.Reindex()
+
+	if g.IsFree(1, 0, 1, 1) {
+		t.Error("IsFree(1, 0) = true, want the span of the written cell")
+	}
+	if got := g.OccupantAt(1, 0); got != cell {
+		t.Errorf("OccupantAt(1, 0) = %v, want the written cell", got)
+	}
+	if err := g.PlaceItem(NewIndicator("b"), 1, 0, 1, 1); err == nil {
+		t.Error("PlaceItem over the written cell succeeded")
+	}
+}
+
+// layoutOf maps every coordinate of the grid to the title of the indicator
+// covering it, "." for free coordinates
+func layoutOf(g *Grid) string {
+	s := ""
+	for r := 0; r < g.Rows; r++ {
+		for c := 0; c < g.Columns; c++ {
+			if cell := g.OccupantAt(r, c); cell != nil {
+				s += cell.Content.(*Indicator).Title
+			} else {
+				s += "."
+			}
+		}
+		s += "|"
+	}
+	return s
+}
+
+func TestMoveItemOntoOccupiedArea(t *testing.T) {
+	g := NewGrid("grid", 2, 3)
+	g.AddItem(NewIndicator("a"), 0, 0, 2, 1)
+	g.AddItem(NewIndicator("b"), 0, 2, 1, 1)
+	before := layoutOf(g)
+
+	var pe *PlacementError
+	err := g.MoveItem(0, 0, 0, 2)
+	if !errors.Is(err, ErrOverlap) || !errors.As(err, &pe) || pe.Conflict != g.Cells[0][2] {
+		t.Fatalf("MoveItem() error = %v, want ErrOverlap with b", err)
+	}
+	if got := layoutOf(g); got != before {
+		t.Errorf("grid = %s after a failed move, want %s", got, before)
+	}
+
+	// Moving over its own previous area is allowed
+	if err := g.MoveItem(1, 0, 0, 1); err != nil {
+		t.Fatalf("MoveItem() error = %v", err)
+	}
+	if got, want := layoutOf(g), ".ab|.a.|"; got != want {
+		t.Errorf("grid = %s, want %s", got, want)
+	}
+}
+
+func TestResizeItemAndReplace(t *testing.T) {
+	g := NewGrid("grid", 2, 2)
+	g.AddItem(NewIndicator("a"), 0, 0, 1, 1)
+	g.AddItem(NewIndicator("b"), 1, 1, 1, 1)
+
+	if err := g.ResizeItem(0, 0, 2, 2); !errors.Is(err, ErrOverlap) {
+		t.Fatalf("ResizeItem() error = %v, want ErrOverlap", err)
+	}
+	if err := g.ResizeItem(0, 0, 1, 2); err != nil {
+		t.Fatalf("ResizeItem() error = %v", err)
+	}
+	if got, want := layoutOf(g), "aa|.b|"; got != want {
+		t.Errorf("grid = %s, want %s", got, want)
+	}
+
+	old, err := g.Replace(0, 1, NewIndicator("c"))
+	if err != nil || old.(*Indicator).Title != "a" {
+		t.Fatalf("Replace() = %v, %v, want a", old, err)
+	}
+	if _, err := g.Replace(0, 0, nil); !errors.Is(err, ErrNilComponent) {
+		t.Errorf("Replace(nil) error = %v, want ErrNilComponent", err)
+	}
+	if got, want := layoutOf(g), "cc|.b|"; got != want {
+		t.Errorf("grid = %s, want %s", got, want)
+	}
+}
+
+func TestSwap(t *testing.T) {
+	g := NewGrid("grid", 3, 3)
+	g.AddItem(NewIndicator("a"), 0, 0, 2, 1)
+	g.AddItem(NewIndicator("b"), 0, 2, 1, 1)
+
+	if err := g.Swap(0, 0, 0, 2); err != nil {
+		t.Fatalf("Swap() error = %v", err)
+	}
+	if got, want := layoutOf(g), "b.a|..a|...|"; got != want {
+		t.Errorf("grid = %s, want %s", got, want)
+	}
+	if err := g.Swap(0, 0, 1, 2); err != nil {
+		t.Fatalf("Swap() back error = %v", err)
+	}
+
+	// a no longer fits at (1, 1) once c sits below it, so nothing moves
+	g.AddItem(NewIndicator("c"), 2, 1, 1, 1)
+	g.AddItem(NewIndicator("d"), 1, 1, 1, 1)
+	before := layoutOf(g)
+	if err := g.Swap(0, 0, 1, 1); !errors.Is(err, ErrOverlap) {
+		t.Fatalf("Swap() error = %v, want ErrOverlap", err)
+	}
+	if got := layoutOf(g); got != before {
+		t.Errorf("grid = %s after a failed swap, want %s", got, before)
+	}
+	if cell := g.Cells[0][0]; cell.Row != 0 || cell.Column != 0 || cell.Content.(*Indicator).Title != "a" {
+		t.Errorf("Cells[0][0] = %+v, want a at its old anchor", cell)
+	}
+	if cell := g.Cells[1][1]; cell.Row != 1 || cell.Column != 1 || cell.Content.(*Indicator).Title != "d" {
+		t.Errorf("Cells[1][1] = %+v, want d at its old anchor", cell)
+	}
+}
+
+func TestRemoveAt(t *testing.T) {
+	g := NewGrid("grid", 2, 2)
+	a := NewIndicator("a")
+	g.AddItem(a, 0, 0, 2, 1)
+
+	var pe *PlacementError
+	if _, err := g.RemoveAt(0, 1); !errors.Is(err, ErrEmptyCell) || !errors.As(err, &pe) {
+		t.Fatalf("RemoveAt() on an empty cell error = %v, want ErrEmptyCell", err)
+	}
+	if _, err := g.RemoveAt(5, 0); !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("RemoveAt() out of range error = %v, want ErrOutOfRange", err)
+	}
+
+	got, err := g.RemoveAt(1, 0)
+	if err != nil || got != Component(a) {
+		t.Fatalf("RemoveAt() = %v, %v, want a", got, err)
+	}
+	if !g.IsFree(0, 0, 2, 2) {
+		t.Errorf("grid = %s after RemoveAt, want it empty", layoutOf(g))
+	}
+}
+
+func TestItemsOrder(t *testing.T) {
+	g := NewGrid("grid", 3, 3)
+	g.AddItem(NewIndicator("c"), 2, 0, 1, 1)
+	g.AddItem(NewIndicator("b"), 0, 2, 2, 1)
+	g.AddItem(NewIndicator("a"), 0, 0, 1, 2)
+	g.AddItem(NewIndicator("d"), 2, 2, 1, 1)
+
+	got := ""
+	for cell := range g.Items() {
+		got += cell.Content.(*Indicator).Title
+		if got == "ab" {
+			// Removing a cell not reached yet skips it
+			g.Remove(g.Cells[2][0].Content.(*Indicator))
+		}
+	}
+	if got != "abd" {
+		t.Errorf("Items() order = %q, want %q", got, "abd")
+	}
+
+	got = ""
+	for cell := range g.Items() {
+		got += cell.Content.(*Indicator).Title
+		break
+	}
+	if got != "a" {
+		t.Errorf("Items() after break = %q, want %q", got, "a")
+	}
+}
+
+func TestPlaceNextAutoGrow(t *testing.T) {
+	g := NewGrid("grid", 1, 3)
+	g.AutoGrow = true
+	g.MaxRows = 4
+
+	steps := []struct {
+		title            string
+		rowSpan, colSpan int
+		rows             int
+		layout           string
+	}{
+		{"a", 1, 2, 1, "aa.|"},
+		{"b", 2, 1, 2, "aab|..b|"},
+		{"c", 1, 2, 2, "aab|ccb|"},
+		{"d", 2, 3, 4, "aab|ccb|ddd|ddd|"},
+	}
+	for _, step := range steps {
+		if err := g.PlaceNext(NewIndicator(step.title), step.rowSpan, step.colSpan); err != nil {
+			t.Fatalf("PlaceNext(%s) error = %v", step.title, err)
+		}
+		if g.Rows != step.rows || len(g.Cells) != step.rows {
+			t.Fatalf("after %s: Rows = %d with %d cell rows, want %d", step.title, g.Rows, len(g.Cells), step.rows)
+		}
+		if got := layoutOf(g); got != step.layout {
+			t.Fatalf("after %s: grid = %s, want %s", step.title, got, step.layout)
+		}
+	}
+
+	if err := g.PlaceNext(NewIndicator("e")); !errors.Is(err, ErrMaxRows) {
+		t.Errorf("PlaceNext() past MaxRows error = %v, want ErrMaxRows", err)
+	}
+	if err := g.PlaceNext(NewIndicator("f"), 1, 4); !errors.Is(err, ErrNoSpace) {
+		t.Errorf("PlaceNext() wider than the grid error = %v, want ErrNoSpace", err)
+	}
+	if g.Rows != 4 {
+		t.Errorf("Rows = %d after failed placements, want 4", g.Rows)
+	}
+	if err := g.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestPlaceNextWithoutAutoGrow(t *testing.T) {
+	g := NewGrid("grid", 1, 1)
+	g.AddNext(NewIndicator("a"))
+	if err := g.PlaceNext(NewIndicator("b")); !errors.Is(err, ErrNoSpace) {
+		t.Fatalf("PlaceNext() error = %v, want ErrNoSpace", err)
+	}
+	if g.Rows != 1 {
+		t.Errorf("Rows = %d, want the grid left as is", g.Rows)
+	}
+}
+
+func TestAutoGrowRoundTrip(t *testing.T) {
+	g := NewGrid("grid", 0, 2)
+	g.AutoGrow = true
+	g.MaxRows = 3
+	g.AddNext(NewIndicator("a"))
+	d := NewDashboard("dashboard", "")
+	d.SetLayout(g)
+
+	loaded, err := LoadDashboard([]byte(d.GenerateJSON()))
+	if err != nil {
+		t.Fatalf("LoadDashboard() error = %v", err)
+	}
+	if !loaded.Layout.AutoGrow || loaded.Layout.MaxRows != 3 || loaded.Layout.Rows != 1 {
+		t.Errorf("decoded grid = %+v, want AutoGrow with MaxRows 3 and one row", loaded.Layout)
+	}
+
+	g.grow(2)
+	g.MaxRows = 1
+	var ve *ValidationError
+	if err := g.Validate(); !errors.As(err, &ve) || ve.Errors[0].Field != "maxRows" {
+		t.Errorf("Validate() = %v, want an error on maxRows", err)
+	}
+}
+
+func TestPlaceItemErrors(t *testing.T) {
+	tests := []struct {
+		name                       string
+		component                  Component
+		row, col, rowSpan, colSpan int
+		want                       error
+	}{
+		{"nil component", nil, 0, 0, 1, 1, ErrNilComponent},
+		{"negative row", NewIndicator("x"), -1, 0, 1, 1, ErrOutOfRange},
+		{"column past the grid", NewIndicator("x"), 0, 3, 1, 1, ErrOutOfRange},
+		{"zero span", NewIndicator("x"), 1, 1, 0, 1, ErrInvalidSpan},
+		{"negative span", NewIndicator("x"), 1, 1, 1, -1, ErrInvalidSpan},
+		{"span overflow", NewIndicator("x"), 1, 2, 1, 2, ErrSpanOverflow},
+		{"overlap", NewIndicator("x"), 0, 1, 1, 1, ErrOverlap},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGrid("grid", 2, 3)
+			occupant := NewIndicator("a")
+			g.AddItem(occupant, 0, 0, 1, 2)
+
+			err := g.PlaceItem(tt.component, tt.row, tt.col, tt.rowSpan, tt.colSpan)
+			var pe *PlacementError
+			if !errors.Is(err, tt.want) || !errors.As(err, &pe) {
+				t.Fatalf("PlaceItem() error = %v, want %v", err, tt.want)
+			}
+			if pe.Row != tt.row || pe.Column != tt.col || pe.RowSpan != tt.rowSpan || pe.ColSpan != tt.colSpan {
+				t.Errorf("PlacementError = %+v, want the requested placement", pe)
+			}
+			if tt.want == ErrOverlap && (pe.Conflict == nil || pe.Conflict.Content != Component(occupant)) {
+				t.Errorf("Conflict = %+v, want the occupant", pe.Conflict)
+			}
+
+			g.AddItem(tt.component, tt.row, tt.col, tt.rowSpan, tt.colSpan)
+			n := 0
+			for _, row := range g.Cells {
+				for _, cell := range row {
+					if cell != nil {
+						n++
+					}
+				}
+			}
+			if n != 1 {
+				t.Errorf("AddItem() left %d cells, want the invalid item ignored", n)
+			}
+		})
+	}
+}
+
+func TestPlaceNextErrors(t *testing.T) {
+	g := NewGrid("grid", 1, 2)
+	if err := g.PlaceNext(nil); !errors.Is(err, ErrNilComponent) {
+		t.Errorf("PlaceNext(nil) error = %v, want ErrNilComponent", err)
+	}
+	if err := g.PlaceNext(NewIndicator("a"), 1, 0); !errors.Is(err, ErrInvalidSpan) {
+		t.Errorf("PlaceNext() zero span error = %v, want ErrInvalidSpan", err)
+	}
+	if err := g.PlaceNext(NewIndicator("a"), 1, 2); err != nil {
+		t.Fatalf("PlaceNext() error = %v", err)
+	}
+
+	err := g.PlaceNext(NewIndicator("b"))
+	var pe *PlacementError
+	if !errors.Is(err, ErrNoSpace) || !errors.As(err, &pe) || pe.RowSpan != 1 || pe.ColSpan != 1 {
+		t.Fatalf("PlaceNext() on a full grid error = %v, want ErrNoSpace for 1x1", err)
+	}
+}
+
+func TestItemsYieldsMovedCellsOnce(t *testing.T) {
+	g := NewGrid("grid", 3, 3)
+	g.AddItem(NewIndicator("a"), 0, 0, 1, 1)
+	g.AddItem(NewIndicator("b"), 2, 2, 1, 1)
+
+	got := ""
+	for cell := range g.Items() {
+		title := cell.Content.(*Indicator).Title
+		got += title
+		if title == "a" {
+			// Moves to an anchor not reached yet
+			if err := g.MoveItem(cell.Row, cell.Column, 1, 1); err != nil {
+				t.Fatal(err)
+			}
+			g.AddItem(NewIndicator("c"), 2, 0, 1, 1)
+		}
+	}
+	if got != "ab" {
+		t.Errorf("Items() = %q, want each cell present at the start once", got)
+	}
+}
+
+func TestEditingHugeSpan(t *testing.T) {
+	g := NewGrid("grid", 2, 2)
+	g.Cells[0][0] = &GridCell{Row: 0, Column: 0, RowSpan: 1 << 30, ColSpan: 1 << 30, Content: NewIndicator("a")}
+	g.Cells[1][1] = &GridCell{Row: 1, Column: 1, RowSpan: 1, ColSpan: 1, Content: NewIndicator("b")}
+	g.Reindex()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := g.Swap(0, 0, 1, 1); err == nil {
+			t.Error("Swap() of a cell running past the grid succeeded")
+		}
+		if _, err := g.RemoveAt(0, 0); err != nil {
+			t.Errorf("RemoveAt() error = %v", err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("editing a cell with a huge span did not return")
+	}
+	if !g.IsFree(0, 0, 1, 2) || g.OccupantAt(1, 1) == nil {
+		t.Errorf("grid = %s, want only b left", layoutOf(g))
+	}
+}
@@ -0,0 +1,246 @@
+package bussola
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultRowHeight is the height of a grid row when the viewport leaves the
+// height open
+const DefaultRowHeight = 150
+
+// Rect is the absolute position and size of a component
+type Rect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Geometry is the result of a layout pass: the rectangle of every cell and
+// the arrangement each grid was laid out with
+type Geometry struct {
+	// Bounds covers the whole layout. It is larger than the viewport when
+	// the minimum sizes of the components need more room.
+	Bounds Rect
+
+	cells        map[*GridCell]Rect
+	arrangements map[*Grid]*Arrangement
+}
+
+// Cell returns the rectangle of a cell
+func (geo *Geometry) Cell(cell *GridCell) (Rect, bool) {
+	r, ok := geo.cells[cell]
+	return r, ok
+}
+
+// Arrangement returns the arrangement a grid was laid out with, or nil when
+// the grid is not part of the layout
+func (geo *Geometry) Arrangement(g *Grid) *Arrangement {
+	return geo.arrangements[g]
+}
+
+// Geometry lays the dashboard out in the viewport without changing it. Each
+// grid uses the breakpoint matching the viewport width. Columns share the
+// width of their grid and rows its height, or are DefaultRowHeight tall when
+// the viewport height is zero, both after Padding and Spacing. A track never
+// shrinks below the MinSize of the components it holds; nested grids need
+// at least the room of their own tracks.
+func (d *Dashboard) Geometry(viewport Size) (*Geometry, error) {
+	if !(viewport.Width > 0) || math.IsInf(viewport.Width, 0) {
+		return nil, fmt.Errorf("bussola: viewport width must be positive, got %g", viewport.Width)
+	}
+	if !(viewport.Height >= 0) || math.IsInf(viewport.Height, 0) {
+		return nil, fmt.Errorf("bussola: viewport height must not be negative, got %g", viewport.Height)
+	}
+
+	geo := &Geometry{
+		Bounds:       Rect{Width: viewport.Width, Height: viewport.Height},
+		cells:        map[*GridCell]Rect{},
+		arrangements: map[*Grid]*Arrangement{},
+	}
+	if d.Layout != nil {
+		geo.Bounds = geo.layout(d.Layout, geo.Bounds, viewport.Width)
+	}
+	return geo, nil
+}
+
+// ComputeLayout lays the dashboard out like Geometry, then moves and resizes
+// the dashboard and every component to its rectangle. Render reports those
+// rectangles as "frame". A component placed in several cells keeps the last
+// one in reading order.
+func (d *Dashboard) ComputeLayout(viewport Size) (*Geometry, error) {
+	geo, err := d.Geometry(viewport)
+	if err != nil {
+		return nil, err
+	}
+
+	place(d, geo.Bounds)
+	if d.Layout != nil {
+		place(d.Layout, geo.Bounds)
+		geo.apply(d.Layout)
+	}
+	return geo, nil
+}
+
+// apply moves and resizes the content of every cell of g
+func (geo *Geometry) apply(g *Grid) {
+	a := geo.arrangements[g]
+	if a == nil {
+		return
+	}
+	for _, ac := range a.Cells {
+		r, ok := geo.cells[ac.Cell]
+		if !ok {
+			continue
+		}
+		place(ac.Cell.Content, r)
+		if nested, ok := ac.Cell.Content.(*Grid); ok {
+			geo.apply(nested)
+		}
+	}
+}
+
+func place(c Component, r Rect) {
+	c.Move(Position{X: r.X, Y: r.Y})
+	c.Resize(Size{Width: r.Width, Height: r.Height})
+}
+
+// layout places the cells of g inside r and returns the rectangle the grid
+// takes, which grows past r when its tracks need more room
+func (geo *Geometry) layout(g *Grid, r Rect, width float64) Rect {
+	a := g.ArrangeFor(width)
+	geo.arrangements[g] = a
+
+	minCols, minRows := g.minTracks(a, width)
+	cols := g.share(r.Width, minCols)
+	rows := g.share(r.Height, minRows)
+	if r.Height == 0 {
+		for i := range rows {
+			rows[i] = max(DefaultRowHeight, minRows[i])
+		}
+	}
+
+	xs, ys := g.offsets(r.X, cols), g.offsets(r.Y, rows)
+	for _, ac := range a.Cells {
+		p := ac.Placement
+		if ac.Cell.Content == nil || p.Row < 0 || p.Row >= len(rows) || p.Column < 0 || p.Column >= len(cols) {
+			continue
+		}
+
+		cell := Rect{
+			X:      xs[p.Column],
+			Y:      ys[p.Row],
+			Width:  g.extent(cols, p.Column, p.ColSpan),
+			Height: g.extent(rows, p.Row, p.RowSpan),
+		}
+		if nested, ok := ac.Cell.Content.(*Grid); ok && nested != nil {
+			cell = geo.layout(nested, cell, width)
+		}
+		geo.cells[ac.Cell] = cell
+	}
+
+	return Rect{
+		X:      r.X,
+		Y:      r.Y,
+		Width:  max(r.Width, g.total(cols)),
+		Height: max(r.Height, g.total(rows)),
+	}
+}
+
+// minTracks returns the minimum width of every column and height of every
+// row of the arrangement. Cells spanning a single track are fitted first;
+// wider spans then spread what they still need evenly over their tracks.
+func (g *Grid) minTracks(a *Arrangement, width float64) (cols, rows []float64) {
+	cols, rows = make([]float64, a.Columns), make([]float64, a.Rows)
+	for _, single := range []bool{true, false} {
+		for _, ac := range a.Cells {
+			if ac.Cell.Content == nil {
+				continue
+			}
+			p := ac.Placement
+			size := minSizeOf(ac.Cell.Content, width)
+			if (p.ColSpan == 1) == single {
+				g.fit(cols, p.Column, p.ColSpan, size.Width)
+			}
+			if (p.RowSpan == 1) == single {
+				g.fit(rows, p.Row, p.RowSpan, size.Height)
+			}
+		}
+	}
+	return cols, rows
+}
+
+// minSizeOf returns the minimum size of a component. A grid needs at least
+// the room of its minimum tracks.
+func minSizeOf(c Component, width float64) Size {
+	g, ok := c.(*Grid)
+	if !ok {
+		return c.MinSize()
+	}
+	if g == nil {
+		return Size{}
+	}
+
+	cols, rows := g.minTracks(g.ArrangeFor(width), width)
+	size := g.MinSize()
+	return Size{Width: max(size.Width, g.total(cols)), Height: max(size.Height, g.total(rows))}
+}
+
+// fit grows the tracks covered by a span evenly until they hold need
+func (g *Grid) fit(tracks []float64, start, span int, need float64) {
+	start, end := max(start, 0), min(start+span, len(tracks))
+	if start >= end {
+		return
+	}
+	if have := g.extent(tracks, start, end-start); need > have {
+		grow := (need - have) / float64(end-start)
+		for i := start; i < end; i++ {
+			tracks[i] += grow
+		}
+	}
+}
+
+// share splits length between the tracks after Padding and Spacing, keeping
+// every track at its minimum
+func (g *Grid) share(length float64, mins []float64) []float64 {
+	tracks := make([]float64, len(mins))
+	if len(mins) == 0 {
+		return tracks
+	}
+	each := (length - 2*g.Padding - float64(len(mins)-1)*g.Spacing) / float64(len(mins))
+	for i, m := range mins {
+		tracks[i] = max(each, m, 0)
+	}
+	return tracks
+}
+
+// offsets returns where each track starts
+func (g *Grid) offsets(start float64, tracks []float64) []float64 {
+	out := make([]float64, len(tracks))
+	at := start + g.Padding
+	for i, t := range tracks {
+		out[i] = at
+		at += t + g.Spacing
+	}
+	return out
+}
+
+// extent returns the length covered by span tracks from start, including
+// the spacing between them
+func (g *Grid) extent(tracks []float64, start, span int) float64 {
+	end := min(start+span, len(tracks))
+	if start >= end {
+		return 0
+	}
+	length := float64(end-start-1) * g.Spacing
+	for _, t := range tracks[start:end] {
+		length += t
+	}
+	return length
+}
+
+// total returns the length of all the tracks with the padding around them
+func (g *Grid) total(tracks []float64) float64 {
+	return 2*g.Padding + g.extent(tracks, 0, len(tracks))
+}
@@ -0,0 +1,61 @@
+package bussola
+
+import "testing"
+
+func TestComputeLayoutHonorsMinSize(t *testing.T) {
+	wide := NewIndicator("wide")
+	wide.SetMinSize(Size{Width: 500, Height: 300})
+	small := NewIndicator("small")
+
+	g := NewGrid("grid", 2, 2)
+	g.AddItem(wide, 0, 0, 1, 1)
+	g.AddItem(small, 1, 1, 1, 1)
+	d := NewDashboard("dashboard", "")
+	d.SetLayout(g)
+
+	geo, err := d.ComputeLayout(Size{Width: 400, Height: 200})
+	if err != nil {
+		t.Fatalf("ComputeLayout() error = %v", err)
+	}
+
+	if f := wide.Frame(); f.Width < 500 || f.Height < 300 {
+		t.Errorf("wide frame = %+v, want at least 500x300", f)
+	}
+	if got := wide.MinSize(); got != (Size{Width: 500, Height: 300}) {
+		t.Errorf("MinSize() = %+v after the layout pass, want the size set by SetMinSize", got)
+	}
+	if got := wide.Size(); got != (Size{Width: wide.Frame().Width, Height: wide.Frame().Height}) {
+		t.Errorf("Size() = %+v, want the size of the frame", got)
+	}
+
+	// The grid grows past the viewport to make room, and the other cells
+	// are placed after the widened tracks
+	want := Rect{X: 15 + 500 + 10, Y: 15 + 300 + 10}
+	if f := small.Frame(); f.X != want.X || f.Y != want.Y {
+		t.Errorf("small frame = %+v, want it at (%g, %g)", f, want.X, want.Y)
+	}
+	if geo.Bounds.Width <= 400 || geo.Bounds.Height <= 200 {
+		t.Errorf("Bounds = %+v, want it larger than the 400x200 viewport", geo.Bounds)
+	}
+	if g.Frame() != geo.Bounds {
+		t.Errorf("grid frame = %+v, want %+v", g.Frame(), geo.Bounds)
+	}
+}
+
+func TestComputeLayoutShrinksAgain(t *testing.T) {
+	w := NewIndicator("w")
+	g := NewGrid("grid", 1, 1)
+	g.AddItem(w, 0, 0, 1, 1)
+	d := NewDashboard("dashboard", "")
+	d.SetLayout(g)
+
+	if _, err := d.ComputeLayout(Size{Width: 1000}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.ComputeLayout(Size{Width: 300}); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Frame().Width; got != 300-2*g.Padding {
+		t.Errorf("width = %g after a narrower pass, want %g", got, 300-2*g.Padding)
+	}
+}
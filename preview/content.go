@@ -0,0 +1,433 @@
+package preview
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+
+	"github.com/isaqueveras/bussola"
+)
+
+const (
+	lineHeight  = 16
+	inset       = 8
+	headerSpace = 24
+)
+
+var (
+	trendUp   = color.RGBA{46, 125, 50, 255}
+	trendDown = color.RGBA{198, 40, 40, 255}
+)
+
+// box is the content area of a widget, below its title
+type box struct {
+	x, y, w, h int
+	bg         color.Color
+}
+
+// drawContent draws the header and a content sketch for the known widget
+// types. It reports false for components it does not know how to draw.
+func drawContent(cv *painter, x, y, w, h int, bg color.Color, name string, component bussola.Component) bool {
+	var title string
+	var draw func(cv *painter, b box)
+
+	switch c := component.(type) {
+	case *bussola.Indicator:
+		title, draw = c.Title, func(cv *painter, b box) { drawIndicator(cv, b, c) }
+	case *bussola.ProgressBar:
+		title, draw = c.Title, func(cv *painter, b box) { drawProgressBar(cv, b, c) }
+	case *bussola.Table:
+		title, draw = c.Title, func(cv *painter, b box) { drawTable(cv, b, c) }
+	case *bussola.Ranking:
+		title, draw = c.Title, func(cv *painter, b box) { drawRanking(cv, b, c) }
+	case *bussola.Chart:
+		title, draw = c.Title, func(cv *painter, b box) { drawChart(cv, b, c) }
+	default:
+		return false
+	}
+
+	typeW := cv.measure(name)
+	cv.text(x+w-inset-typeW, y+16, name, cv.pal.muted)
+	cv.text(x+inset, y+16, fit(cv, title, w-3*inset-typeW), cv.pal.text)
+
+	b := box{x: x + inset, y: y + headerSpace, w: w - 2*inset, h: h - headerSpace - inset, bg: bg}
+	if b.w <= 0 || b.h <= 0 {
+		return true
+	}
+
+	if w, ok := component.(interface{ Err() error }); ok && w.Err() != nil {
+		drawError(cv, b, w.Err())
+		return true
+	}
+	draw(cv, b)
+	return true
+}
+
+// drawError replaces the content of a widget whose data failed to load
+func drawError(cv *painter, b box, err error) {
+	cv.strokeRect(b.x, b.y, b.w, b.h, trendDown)
+	label := fit(cv, "error: "+err.Error(), b.w-8)
+	cv.text(b.x+4, b.y+b.h/2+4, label, trendDown)
+}
+
+func drawIndicator(cv *painter, b box, ind *bussola.Indicator) {
+	value := format(ind.Value)
+	if ind.Unit != "" {
+		value += " " + ind.Unit
+	}
+	value = fit(cv, value, b.w)
+	cy := b.y + b.h/2
+	cv.text(b.x+(b.w-cv.measure(value))/2, cy, value, cv.pal.text)
+
+	if ind.Trend != 0 {
+		label := strconv.FormatFloat(math.Abs(ind.Trend), 'f', 1, 64) + "%"
+		width := 10 + cv.measure(label)
+		tx := b.x + (b.w-width)/2
+		ty := cy + lineHeight
+		if ind.Trend > 0 {
+			cv.fillPolygon([]image.Point{{tx, ty}, {tx + 8, ty}, {tx + 4, ty - 8}}, trendUp)
+			cv.text(tx+10, ty, label, trendUp)
+		} else {
+			cv.fillPolygon([]image.Point{{tx, ty - 8}, {tx + 8, ty - 8}, {tx + 4, ty}}, trendDown)
+			cv.text(tx+10, ty, label, trendDown)
+		}
+	}
+
+	if ind.Description != "" && b.h > 3*lineHeight {
+		desc := fit(cv, ind.Description, b.w)
+		cv.text(b.x+(b.w-cv.measure(desc))/2, b.y+b.h-4, desc, cv.pal.muted)
+	}
+}
+
+func drawProgressBar(cv *painter, b box, p *bussola.ProgressBar) {
+	percent := p.Percent() / 100
+
+	const barHeight = 12
+	by := b.y + (b.h-barHeight)/2
+	cv.fillRect(b.x, by, b.w, barHeight, withAlpha(cv.pal.text, 30))
+
+	if len(p.Segments) > 0 {
+		span := p.MaxValue - p.MinValue
+		done := 0.0
+		for i, s := range p.Segments {
+			if !(span > 0) {
+				break
+			}
+			start := int(float64(b.w) * math.Min(1, done/span))
+			done += math.Max(0, s.Value)
+			end := int(float64(b.w) * math.Min(1, done/span))
+			c := cv.pal.slices[i%len(cv.pal.slices)]
+			if parsed, err := bussola.ParseHexColor(s.Color); err == nil {
+				c = parsed
+			}
+			if end > start {
+				cv.fillRect(b.x+start, by, end-start, barHeight, c)
+			}
+		}
+	} else if fill := int(float64(b.w) * percent); fill > 0 {
+		fillColor := cv.pal.accent
+		if band := p.Band(); band != nil {
+			if parsed, err := bussola.ParseHexColor(band.Color); err == nil {
+				fillColor = parsed
+			}
+		}
+		cv.fillRect(b.x, by, fill, barHeight, fillColor)
+	}
+
+	if p.Target != nil && p.MaxValue > p.MinValue {
+		t := math.Max(0, math.Min(1, (*p.Target-p.MinValue)/(p.MaxValue-p.MinValue)))
+		tx := b.x + int(float64(b.w-1)*t)
+		cv.line(tx, by-4, tx, by+barHeight+3, cv.pal.text)
+	}
+
+	if p.ShowPercent {
+		label := strconv.FormatFloat(percent*100, 'f', 0, 64) + "%"
+		if band := p.Band(); band != nil && band.Name != "" {
+			label += " - " + band.Name
+		}
+		cv.text(b.x+(b.w-cv.measure(label))/2, by+barHeight+lineHeight, label, cv.pal.text)
+	}
+}
+
+func drawTable(cv *painter, b box, t *bussola.Table) {
+	columns := t.VisibleColumns()
+	if len(columns) == 0 {
+		return
+	}
+
+	colW := b.w / len(columns)
+	cellText := func(i, y int, label string, c color.Color, align bussola.Align) {
+		label = fit(cv, label, colW-8)
+		x := b.x + i*colW + 4
+		switch align {
+		case bussola.AlignRight:
+			x = b.x + (i+1)*colW - 4 - cv.measure(label)
+		case bussola.AlignCenter:
+			x = b.x + i*colW + (colW-cv.measure(label))/2
+		}
+		cv.text(x, y, label, c)
+	}
+
+	cv.fillRect(b.x, b.y, b.w, lineHeight+2, withAlpha(cv.pal.text, 20))
+	for i, col := range columns {
+		cellText(i, b.y+12, col.Label, cv.pal.text, col.Align)
+	}
+
+	y := b.y + lineHeight + 2
+	for _, row := range t.CurrentRows() {
+		if y+lineHeight > b.y+b.h {
+			break
+		}
+		for i, col := range columns {
+			label := format(nil)
+			if v := col.Value(row); v != nil {
+				label = col.FormatValue(v)
+			}
+			cellText(i, y+12, label, cv.pal.text, col.Align)
+		}
+		y += lineHeight
+		cv.line(b.x, y, b.x+b.w-1, y, withAlpha(cv.pal.text, 20))
+	}
+}
+
+func drawRanking(cv *painter, b box, r *bussola.Ranking) {
+	y := b.y
+	for _, item := range r.Items {
+		if y+lineHeight > b.y+b.h {
+			break
+		}
+		label := strconv.Itoa(item.Position) + ". " + item.Title
+		label = fit(cv, label, b.w)
+		cv.text(b.x, y+12, label, cv.pal.text)
+
+		if item.Description != "" {
+			offset := cv.measure(label) + 7
+			if desc := fit(cv, item.Description, b.w-offset); desc != "" {
+				cv.text(b.x+offset, y+12, desc, cv.pal.muted)
+			}
+		}
+		y += lineHeight
+	}
+}
+
+func drawChart(cv *painter, b box, c *bussola.Chart) {
+	n := c.Normalized()
+	if len(n.Series) == 0 {
+		label := "no data"
+		cv.text(b.x+(b.w-cv.measure(label))/2, b.y+b.h/2, label, cv.pal.muted)
+		return
+	}
+
+	colors := make([]color.Color, len(n.Series))
+	for i, s := range n.Series {
+		colors[i] = cv.pal.slices[i%len(cv.pal.slices)]
+		if parsed, err := bussola.ParseHexColor(s.Color); err == nil {
+			colors[i] = parsed
+		}
+	}
+
+	if n.Type.Circular() {
+		drawPie(cv, b, n.Series[0].Data, n.Type == bussola.ChartDonut)
+		return
+	}
+
+	peak, points := 0.0, 0
+	stack := map[int]float64{}
+	for _, s := range n.Series {
+		points = max(points, len(s.Data))
+		for i, v := range s.Data {
+			v = math.Max(finite(v), 0)
+			if n.Stacked {
+				stack[i] += v
+				v = stack[i]
+			}
+			peak = math.Max(peak, v)
+		}
+	}
+	if n.YAxis != nil && n.YAxis.Max != nil && *n.YAxis.Max > 0 {
+		peak = *n.YAxis.Max
+	}
+	if peak == 0 {
+		peak = 1
+	}
+	if points == 0 {
+		return
+	}
+
+	bottom := b.y + b.h - 1
+	cv.line(b.x, b.y, b.x, bottom, cv.pal.muted)
+	cv.line(b.x, bottom, b.x+b.w-1, bottom, cv.pal.muted)
+
+	plotW, plotH := b.w-4, b.h-4
+	scaled := func(v float64) int {
+		return int(math.Min(math.Max(finite(v), 0), peak) / peak * float64(plotH-2))
+	}
+
+	if n.Type == bussola.ChartBar {
+		slot := plotW / points
+		width := max(slot*2/3, 1)
+		if !n.Stacked {
+			width = max(width/len(n.Series), 1)
+		}
+		base := make([]int, points)
+		for si, s := range n.Series {
+			for i, v := range s.Data {
+				bh := scaled(v)
+				x, y := b.x+4+i*slot+slot/6, bottom-bh
+				if n.Stacked {
+					y -= base[i]
+					base[i] += bh
+				} else {
+					x += si * width
+				}
+				cv.fillRect(x, y, width, bh, colors[si])
+			}
+		}
+		return
+	}
+
+	base := map[int]float64{}
+	for si, s := range n.Series {
+		// Points without an X value cannot be placed
+		data := s.Data
+		if s.X != nil {
+			data = data[:min(len(data), len(s.X))]
+		}
+		step := 0.0
+		if len(data) > 1 {
+			step = float64(plotW) / float64(len(data)-1)
+		}
+		lo, hi := span(s.X)
+
+		pts := make([]image.Point, len(data))
+		for i, v := range data {
+			if n.Stacked {
+				base[i] += math.Max(finite(v), 0)
+				v = base[i]
+			}
+			x := float64(i) * step
+			if s.X != nil {
+				x = math.Min(math.Max((finite(s.X[i])-lo)/(hi-lo), 0), 1) * float64(plotW)
+			}
+			pts[i] = image.Point{X: b.x + 2 + int(x), Y: bottom - 2 - scaled(v)}
+		}
+
+		if n.Type == bussola.ChartArea && len(pts) > 0 {
+			area := append([]image.Point{{pts[0].X, bottom}}, pts...)
+			area = append(area, image.Point{pts[len(pts)-1].X, bottom})
+			cv.fillPolygon(area, withAlpha(colors[si], 80))
+		}
+		if n.Type != bussola.ChartScatter {
+			for i := 1; i < len(pts); i++ {
+				p0, p1 := pts[i-1], pts[i]
+				cv.line(p0.X, p0.Y, p1.X, p1.Y, colors[si])
+				cv.line(p0.X, p0.Y+1, p1.X, p1.Y+1, colors[si])
+			}
+		}
+		for _, p := range pts {
+			cv.fillRect(p.X-2, p.Y-2, 5, 5, colors[si])
+		}
+	}
+}
+
+// span returns the bounds of the finite values, which are 0 and 1 when there
+// are none
+func span(values []float64) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if v == finite(v) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	if lo > hi {
+		return 0, 1
+	}
+	if lo == hi {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+func drawPie(cv *painter, b box, values []float64, donut bool) {
+	total := 0.0
+	for _, v := range values {
+		total += math.Max(finite(v), 0)
+	}
+	if total == 0 {
+		return
+	}
+
+	r := float64(min(b.w, b.h)) / 2
+	cx, cy := float64(b.x)+float64(b.w)/2, float64(b.y)+float64(b.h)/2
+	angle := -math.Pi / 2
+	for i, v := range values {
+		if v = finite(v); v <= 0 {
+			continue
+		}
+		sweep := v / total * 2 * math.Pi
+		cv.fillPolygon(wedge(cx, cy, r, angle, angle+sweep), cv.pal.slices[i%len(cv.pal.slices)])
+		angle += sweep
+	}
+
+	if donut {
+		cv.fillPolygon(wedge(cx, cy, r/2, 0, 2*math.Pi)[1:], b.bg)
+	}
+}
+
+// finite returns v, or 0 when v is NaN or infinite. Such values would
+// otherwise scale to coordinates far off the canvas.
+func finite(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}
+
+// wedge approximates a circle sector with a polygon
+func wedge(cx, cy, r, from, to float64) []image.Point {
+	steps := max(int((to-from)/(math.Pi/32)), 1)
+	points := []image.Point{{int(cx), int(cy)}}
+	for i := 0; i <= steps; i++ {
+		a := from + (to-from)*float64(i)/float64(steps)
+		points = append(points, image.Point{int(math.Round(cx + r*math.Cos(a))), int(math.Round(cy + r*math.Sin(a)))})
+	}
+	return points
+}
+
+// withAlpha returns c with its opacity replaced by alpha
+func withAlpha(c color.Color, alpha uint8) color.Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	n.A = alpha
+	return n
+}
+
+// fit shortens s with ".." so that it is at most width pixels wide
+func fit(cv canvas, s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if cv.measure(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for n := len(runes) - 1; n > 0; n-- {
+		if t := string(runes[:n]) + ".."; cv.measure(t) <= width {
+			return t
+		}
+	}
+	return ""
+}
+
+func format(v any) string {
+	switch n := v.(type) {
+	case nil:
+		return "--"
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(n), 'f', -1, 32)
+	}
+	return fmt.Sprint(v)
+}
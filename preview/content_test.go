@@ -0,0 +1,99 @@
+package preview
+
+import (
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/isaqueveras/bussola"
+)
+
+// widgetGallery returns one dashboard cell per widget variant the preview
+// draws, including empty and degenerate data
+func widgetGallery() []bussola.Component {
+	indicator := bussola.NewIndicator("users")
+	indicator.Value, indicator.Unit, indicator.Trend, indicator.Description = 1234.5, "k", -3.2, "active users"
+	rising := bussola.NewIndicator("rising")
+	rising.Value, rising.Trend = "n/a", 12
+
+	progress := bussola.NewProgressBar("progress")
+	progress.Value = 150
+	thresholds := bussola.NewProgressBar("thresholds")
+	thresholds.Value = 40
+	thresholds.Thresholds = []bussola.Threshold{{Name: "low", Below: 30, Color: "#f00"}, {Name: "ok", Below: 100, Color: "#0f0"}}
+	segments := bussola.NewProgressBar("segments")
+	segments.Segments = []bussola.Segment{{Name: "a", Value: 30, Color: "#00f"}, {Name: "b", Value: -5}, {Name: "c", Value: 200}}
+	empty := bussola.NewProgressBar("empty range")
+	empty.MinValue, empty.MaxValue = 10, 10
+
+	table := bussola.NewTable("table", []string{"Name", "Amount"})
+	table.Data = []map[string]any{{"name": "a", "amount": 1.5}, {"name": "b"}, {"other": true}}
+
+	ranking := bussola.NewRanking("ranking")
+	ranking.AddItem(bussola.NewRankingItem(1, "first", "the best", "a.png"))
+	ranking.AddItem(bussola.NewRankingItem(2, "second", "", ""))
+
+	line := bussola.NewChart("line", bussola.ChartLine)
+	line.SetCategories("a", "b", "c")
+	line.AddSeries("one", "#336699", 1, 5, 2)
+	line.AddSeries("two", "", -1, math.NaN(), math.Inf(1))
+	stacked := bussola.NewChart("stacked", bussola.ChartBar)
+	stacked.Stacked = true
+	stacked.AddSeries("one", "", 1, 2)
+	stacked.AddSeries("two", "", 3, -4)
+	area := bussola.NewChart("area", bussola.ChartArea)
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	area.SetTimes(day, day.AddDate(0, 0, 1))
+	area.AddSeries("visits", "", 0, 0)
+	scatter := bussola.NewChart("scatter", bussola.ChartScatter)
+	scatter.Series = []bussola.Series{{Name: "s", Data: []float64{1, 2, math.NaN()}, X: []float64{3, 3, math.Inf(-1)}}}
+	pie := bussola.NewChart("pie", bussola.ChartPie)
+	pie.AddSeries("share", "", 3, 1, 0, math.Inf(1))
+	donut := bussola.NewChart("donut", bussola.ChartDonut)
+	donut.AddSeries("share", "", 0, 0)
+	legacy := bussola.NewChart("legacy", bussola.ChartLine)
+	legacy.Data = []any{1.0, 2.0, "x"}
+	noData := bussola.NewChart("no data", bussola.ChartBar)
+
+	return []bussola.Component{
+		indicator, rising, bussola.NewIndicator("blank"),
+		progress, thresholds, segments, empty,
+		table, bussola.NewTable("empty table", nil),
+		ranking, bussola.NewRanking("empty ranking"),
+		line, stacked, area, scatter, pie, donut, legacy, noData,
+	}
+}
+
+func TestImageDrawsEveryWidget(t *testing.T) {
+	widgets := widgetGallery()
+	grid := bussola.NewGrid("grid", len(widgets), 1)
+	for i, w := range widgets {
+		grid.AddItem(w, i, 0, 1, 1)
+	}
+	dashboard := bussola.NewDashboard("dashboard", "")
+	dashboard.SetLayout(grid)
+
+	sizes := []struct {
+		name string
+		opts *Options
+	}{
+		{"default", nil},
+		{"tiny cells", &Options{CellWidth: 8, CellHeight: 8}},
+		{"wide cells", &Options{CellWidth: 900, CellHeight: 400}},
+	}
+	for _, tt := range sizes {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Image(dashboard, tt.opts)
+			if err != nil {
+				t.Fatalf("Image() error = %v", err)
+			}
+			if b := img.Bounds(); b.Empty() {
+				t.Errorf("Image() bounds = %v, want a drawn image", b)
+			}
+			if err := GenerateSVG(dashboard, io.Discard, tt.opts); err != nil {
+				t.Errorf("GenerateSVG() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package preview
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+// defaultFontSize is the size, in points at 72 DPI, used for registered fonts
+const defaultFontSize = 12
+
+var fonts = struct {
+	sync.RWMutex
+	byFamily map[string]*opentype.Font
+}{byFamily: map[string]*opentype.Font{}}
+
+func init() {
+	regular, _ := opentype.Parse(goregular.TTF)
+	mono, _ := opentype.Parse(gomono.TTF)
+	fonts.byFamily["go"] = regular
+	fonts.byFamily["go mono"] = mono
+}
+
+// RegisterFont makes a TTF or OTF font available to previews of dashboards
+// whose Theme.FontFamily lists the given family name
+func RegisterFont(family string, data []byte) error {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return fmt.Errorf("preview: font %q: %w", family, err)
+	}
+
+	fonts.Lock()
+	defer fonts.Unlock()
+	fonts.byFamily[fontKey(family)] = f
+	return nil
+}
+
+// RegisterFontFile reads a TTF or OTF file and registers it under family
+func RegisterFontFile(family, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return RegisterFont(family, data)
+}
+
+// resolveFace returns a face for the first registered family in a CSS-like
+// font list such as "Inter, sans-serif", falling back to the built-in
+// bitmap font. Faces are not safe for concurrent use, so each preview gets
+// its own.
+func resolveFace(families string, size float64) font.Face {
+	if size <= 0 {
+		size = defaultFontSize
+	}
+
+	fonts.RLock()
+	defer fonts.RUnlock()
+	for _, family := range strings.Split(families, ",") {
+		f, ok := fonts.byFamily[fontKey(family)]
+		if !ok {
+			continue
+		}
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err == nil {
+			return face
+		}
+	}
+	return basicfont.Face7x13
+}
+
+func fontKey(family string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(family), `"'`))
+}
@@ -0,0 +1,46 @@
+package preview
+
+import (
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestRegisterFont(t *testing.T) {
+	if err := RegisterFont("Test Sans", goregular.TTF); err != nil {
+		t.Fatalf("RegisterFont() error = %v", err)
+	}
+	t.Cleanup(func() {
+		fonts.Lock()
+		delete(fonts.byFamily, "test sans")
+		fonts.Unlock()
+	})
+
+	if err := RegisterFont("Broken", []byte("not a font")); err == nil {
+		t.Error("RegisterFont() of invalid data succeeded")
+	}
+
+	tests := []struct {
+		families string
+		builtin  bool
+	}{
+		{"Test Sans", false},
+		{`"test sans", sans-serif`, false},
+		{"Unknown, 'Test Sans'", false},
+		{"Go Mono", false},
+		{"Broken, sans-serif", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		face := resolveFace(tt.families, 0)
+		if builtin := face == basicfont.Face7x13; builtin != tt.builtin {
+			t.Errorf("resolveFace(%q) built-in = %v, want %v", tt.families, builtin, tt.builtin)
+		}
+	}
+
+	small, large := resolveFace("Test Sans", 10), resolveFace("Test Sans", 30)
+	if small.Metrics().Height >= large.Metrics().Height {
+		t.Errorf("resolveFace() heights %v and %v do not follow the size", small.Metrics().Height, large.Metrics().Height)
+	}
+}
@@ -1,87 +1,263 @@
 package preview
 
 import (
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
-	"image/draw"
 	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/isaqueveras/bussola"
 
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/basicfont"
-	"golang.org/x/image/math/fixed"
 )
 
 const (
 	cellWidth  = 200
 	cellHeight = 150
-	padding    = 20
-	margin     = 10
 )
 
-// GeneratePreview creates a preview image of the dashboard layout
-func GeneratePreview(dashboard *bussola.Dashboard, outputPath string) error {
-	if dashboard.Layout == nil {
-		return nil
+// Format selects the encoding used by Encode
+type Format int
+
+const (
+	FormatJPEG Format = iota
+	FormatPNG
+	FormatSVG
+)
+
+// ErrNoLayout is returned when the dashboard has no layout to draw
+var ErrNoLayout = errors.New("preview: dashboard has no layout")
+
+// Options configures the generated preview
+type Options struct {
+	Format     Format // output format, defaults to JPEG
+	Quality    int    // JPEG quality from 1 to 100, defaults to 90
+	CellWidth  int    // width of a single grid column, defaults to 200
+	CellHeight int    // height of a single grid row, defaults to 150
+
+	// FontSize is the text size used with fonts registered through
+	// RegisterFont, defaults to 12
+	FontSize float64
+
+	// Width is the viewport width the layout is computed for, which also
+	// picks the breakpoint of each grid. Zero uses the width of the declared
+	// columns at CellWidth.
+	Width int
+
+	// Ghosts draws hidden cells as dashed outlines instead of skipping them
+	Ghosts bool
+
+	// Filters is the filter state used to evaluate ShowWhen rules
+	Filters bussola.FilterValues
+}
+
+// visible reports whether a component is drawn
+func (o *Options) visible(c bussola.Component) bool {
+	vis := &bussola.RenderOptions{}
+	if o != nil {
+		vis.Filters = o.Filters
+	}
+	return vis.Visible(c)
+}
+
+// viewport returns the size the dashboard is laid out in. Without a Width
+// it fits the declared columns at CellWidth; rows are CellHeight tall.
+func (o *Options) viewport(grid *bussola.Grid) bussola.Size {
+	cw, ch := cellWidth, cellHeight
+	if o != nil && o.CellWidth > 0 {
+		cw = o.CellWidth
+	}
+	if o != nil && o.CellHeight > 0 {
+		ch = o.CellHeight
+	}
+
+	width := float64(grid.Columns*cw) + float64(max(grid.Columns-1, 0))*grid.Spacing + 2*grid.Padding
+	if o != nil && o.Width > 0 {
+		width = float64(o.Width)
+	}
+	rows := grid.ArrangeFor(width).Rows
+	return bussola.Size{
+		Width:  max(width, 1),
+		Height: float64(rows*ch) + float64(max(rows-1, 0))*grid.Spacing + 2*grid.Padding,
+	}
+}
+
+// canvas is the drawing surface shared by the raster and vector backends
+type canvas interface {
+	fillRect(x, y, w, h int, c color.Color)
+	strokeRect(x, y, w, h int, c color.Color)
+	line(x0, y0, x1, y1 int, c color.Color)
+	fillPolygon(points []image.Point, c color.Color)
+	text(x, y int, s string, c color.Color)
+	measure(s string) int
+}
+
+// painter draws on a canvas with the colors of a palette
+type painter struct {
+	canvas
+	pal  palette
+	opts *Options
+	geo  *bussola.Geometry
+}
+
+// cell draws a grid cell, honoring the visibility of its content
+func (cv *painter) cell(x, y, w, h int, component bussola.Component) {
+	if cv.opts.visible(component) {
+		drawComponent(cv, x, y, w, h, cv.pal.componentColor(component), getComponentName(component), component)
+		return
+	}
+	if cv.opts != nil && cv.opts.Ghosts {
+		drawGhost(cv, x, y, w, h, getComponentName(component))
 	}
+}
 
-	grid := dashboard.Layout
-	totalWidth := grid.Columns*cellWidth + (grid.Columns+1)*margin
-	totalHeight := grid.Rows*cellHeight + (grid.Rows+1)*margin
+// drawGhost outlines a hidden component with a dashed border
+func drawGhost(cv *painter, x, y, w, h int, name string) {
+	const dash = 6
+	c := withAlpha(cv.pal.muted, 160)
+	for i := 0; i < w; i += 2 * dash {
+		cv.line(x+i, y, x+min(i+dash, w), y, c)
+		cv.line(x+i, y+h, x+min(i+dash, w), y+h, c)
+	}
+	for i := 0; i < h; i += 2 * dash {
+		cv.line(x, y+i, x, y+min(i+dash, h), c)
+		cv.line(x+w, y+i, x+w, y+min(i+dash, h), c)
+	}
 
-	// Create a new white image
-	img := image.NewRGBA(image.Rect(0, 0, totalWidth, totalHeight))
-	draw.Draw(img, img.Bounds(), &image.Uniform{color.White}, image.Point{}, draw.Src)
+	label := "hidden"
+	if name != "" {
+		label = name + " (hidden)"
+	}
+	cv.text(x+(w-cv.measure(label))/2, y+h/2, label, c)
+}
 
-	// Draw grid lines
-	gridColor := color.RGBA{255, 255, 255, 255}
-	for row := 0; row <= grid.Rows; row++ {
-		y := row * (cellHeight + margin)
-		drawHorizontalLine(img, y, totalWidth, gridColor)
+func (o *Options) fontSize() float64 {
+	if o == nil {
+		return 0
 	}
-	for col := 0; col <= grid.Columns; col++ {
-		x := col * (cellWidth + margin)
-		drawVerticalLine(img, x, totalHeight, gridColor)
+	return o.FontSize
+}
+
+func (o *Options) quality() int {
+	if o == nil || o.Quality <= 0 || o.Quality > 100 {
+		return 90
 	}
+	return o.Quality
+}
 
-	// Draw cells with components
-	for row := range grid.Cells {
-		for col := range grid.Cells[row] {
-			cell := grid.Cells[row][col]
-			if cell != nil && cell.Content != nil {
-				x := col*(cellWidth+margin) + margin
-				y := row*(cellHeight+margin) + margin
-				w := cell.ColSpan*cellWidth + (cell.ColSpan-1)*margin
-				h := cell.RowSpan*cellHeight + (cell.RowSpan-1)*margin
+// GeneratePreview creates a preview image of the dashboard layout. The format
+// follows the file extension: .png, .svg or JPEG for anything else. It
+// returns ErrNoLayout without creating the file when there is nothing to draw.
+func GeneratePreview(dashboard *bussola.Dashboard, outputPath string) error {
+	if dashboard.Layout == nil {
+		return ErrNoLayout
+	}
 
-				// Draw component rectangle
-				drawComponent(img, x, y, w, h, getComponentColor(cell.Content), getComponentName(cell.Content), cell.Content)
-			}
-		}
+	opts := &Options{Format: FormatJPEG}
+	switch strings.ToLower(filepath.Ext(outputPath)) {
+	case ".png":
+		opts.Format = FormatPNG
+	case ".svg":
+		opts.Format = FormatSVG
 	}
 
 	f, err := os.Create(outputPath)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	return jpeg.Encode(f, img, &jpeg.Options{
-		Quality: 90,
-	})
+	if err := Encode(dashboard, f, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
-func drawHorizontalLine(img *image.RGBA, y, width int, c color.Color) {
-	for x := 0; x < width; x++ {
-		img.Set(x, y, c)
+// Encode writes a preview of the dashboard layout to w in the format selected
+// by opts. A nil opts writes a JPEG with the default settings.
+func Encode(dashboard *bussola.Dashboard, w io.Writer, opts *Options) error {
+	format := FormatJPEG
+	if opts != nil {
+		format = opts.Format
+	}
+
+	if format == FormatSVG {
+		return GenerateSVG(dashboard, w, opts)
+	}
+
+	img, err := Image(dashboard, opts)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatJPEG:
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: opts.quality()})
+	case FormatPNG:
+		return png.Encode(w, img)
+	default:
+		return fmt.Errorf("preview: unsupported format %d", format)
 	}
 }
 
-func drawVerticalLine(img *image.RGBA, x, height int, c color.Color) {
-	for y := 0; y < height; y++ {
-		img.Set(x, y, c)
+// Image draws the dashboard layout and returns the resulting image
+func Image(dashboard *bussola.Dashboard, opts *Options) (image.Image, error) {
+	if dashboard.Layout == nil {
+		return nil, ErrNoLayout
+	}
+
+	geo, err := dashboard.Geometry(opts.viewport(dashboard.Layout))
+	if err != nil {
+		return nil, err
+	}
+
+	pal := newPalette(dashboard.Theme)
+	width, height := canvasSize(geo)
+	cv := newRasterCanvas(width, height, pal.background, dashboardFace(dashboard, opts))
+	drawDashboard(&painter{canvas: cv, pal: pal, opts: opts, geo: geo}, dashboard)
+	return cv.img, nil
+}
+
+// canvasSize returns the total size needed to draw the layout
+func canvasSize(geo *bussola.Geometry) (int, int) {
+	return int(math.Ceil(geo.Bounds.X + geo.Bounds.Width)), int(math.Ceil(geo.Bounds.Y + geo.Bounds.Height))
+}
+
+// dashboardFace returns the font face matching the dashboard theme
+func dashboardFace(dashboard *bussola.Dashboard, opts *Options) font.Face {
+	if dashboard.Theme == nil {
+		return basicfont.Face7x13
+	}
+	return resolveFace(dashboard.Theme.FontFamily, opts.fontSize())
+}
+
+// drawDashboard draws every cell of the dashboard layout onto the canvas
+func drawDashboard(cv *painter, dashboard *bussola.Dashboard) {
+	cv.grid(dashboard.Layout)
+}
+
+// grid draws the cells of a grid at the rectangles of the layout
+func (cv *painter) grid(grid *bussola.Grid) {
+	a := cv.geo.Arrangement(grid)
+	if a == nil {
+		return
+	}
+	for _, ac := range a.Cells {
+		r, ok := cv.geo.Cell(ac.Cell)
+		if !ok {
+			continue
+		}
+		x, y := int(math.Round(r.X)), int(math.Round(r.Y))
+		w, h := int(math.Round(r.X+r.Width))-x, int(math.Round(r.Y+r.Height))-y
+		cv.cell(x, y, w, h, ac.Cell.Content)
 	}
 }
 
@@ -104,80 +280,96 @@ func getComponentName(component bussola.Component) string {
 	}
 }
 
-func drawComponent(img *image.RGBA, x, y, w, h int, c color.Color, name string, comp ...bussola.Component) {
+func getFilterName(filter bussola.Filter) string {
+	switch filter.(type) {
+	case *bussola.FilterDate:
+		return "date"
+	case *bussola.FilterSelect:
+		return "select"
+	case *bussola.FilterText:
+		return "text"
+	case *bussola.FilterBool:
+		return "bool"
+	case *bussola.FilterSearch:
+		return "search"
+	case *bussola.FilterCheckbox:
+		return "checkbox"
+	case *bussola.FilterRadio:
+		return "radio"
+	case *bussola.FilterMultiSelect:
+		return "multi-select"
+	case *bussola.FilterBar:
+		return "filter-bar"
+	case *bussola.FilterNumber:
+		return "number"
+	case *bussola.FilterRange:
+		return "range"
+	case *bussola.FilterToggle:
+		return "toggle"
+	case *bussola.FilterSlider:
+		return "slider"
+	case *bussola.FilterColor:
+		return "color"
+	default:
+		return ""
+	}
+}
+
+func getFilterColor(filter bussola.Filter) color.Color {
+	switch filter.(type) {
+	case *bussola.FilterDate:
+		return color.RGBA{200, 230, 255, 255}
+	case *bussola.FilterSelect:
+		return color.RGBA{220, 255, 200, 255}
+	case *bussola.FilterText:
+		return color.RGBA{255, 255, 200, 255}
+	case *bussola.FilterBool:
+		return color.RGBA{255, 220, 220, 255}
+	case *bussola.FilterSearch:
+		return color.RGBA{220, 200, 255, 255}
+	case *bussola.FilterCheckbox:
+		return color.RGBA{255, 240, 200, 255}
+	case *bussola.FilterRadio:
+		return color.RGBA{255, 200, 240, 255}
+	case *bussola.FilterMultiSelect:
+		return color.RGBA{240, 200, 255, 255}
+	case *bussola.FilterBar:
+		return color.RGBA{220, 220, 220, 255}
+	case *bussola.FilterNumber:
+		return color.RGBA{255, 255, 200, 255}
+	case *bussola.FilterRange:
+		return color.RGBA{200, 255, 200, 255}
+	case *bussola.FilterToggle:
+		return color.RGBA{255, 220, 200, 255}
+	case *bussola.FilterSlider:
+		return color.RGBA{200, 255, 220, 255}
+	case *bussola.FilterColor:
+		return color.RGBA{220, 220, 255, 255}
+	default:
+		return color.RGBA{240, 240, 240, 255}
+	}
+}
+
+func drawComponent(cv *painter, x, y, w, h int, c color.Color, name string, comp ...bussola.Component) {
 	var component bussola.Component
 	if len(comp) > 0 {
 		component = comp[0]
 	}
 
 	if grid, ok := component.(*bussola.Grid); ok {
-		rows := grid.Rows
-		cols := grid.Columns
-		if rows == 0 || cols == 0 {
-			return
-		}
-		cellW := w / cols
-		cellH := h / rows
-		for row := range grid.Cells {
-			for col := range grid.Cells[row] {
-				cell := grid.Cells[row][col]
-				if cell != nil && cell.Content != nil {
-					x0 := x + col*cellW
-					y0 := y + row*cellH
-					cw := cell.ColSpan * cellW
-					ch := cell.RowSpan * cellH
-					drawComponent(img, x0, y0, cw, ch, getComponentColor(cell.Content), getComponentName(cell.Content), cell.Content)
-				}
-			}
-		}
+		cv.grid(grid)
 
-		face := basicfont.Face7x13
-		label := name
-		labelWidth := font.MeasureString(face, label).Ceil()
-		labelX := x + (w-labelWidth)/2
-		labelY := y + 15
-		col := color.RGBA{30, 30, 30, 255}
-		d := &font.Drawer{
-			Dst:  img,
-			Src:  image.NewUniform(col),
-			Face: face,
-			Dot:  fixed.P(labelX, labelY),
-		}
-		d.DrawString(label)
+		labelWidth := cv.measure(name)
+		cv.text(x+(w-labelWidth)/2, y+15, name, cv.pal.text)
 		return
 	}
 
 	if filterBar, ok := component.(*bussola.FilterBar); ok {
-		for i := x; i < x+w; i++ {
-			for j := y; j < y+h; j++ {
-				img.Set(i, j, c)
-			}
-		}
-
-		borderColor := color.RGBA{100, 100, 100, 255}
-		for i := x; i < x+w; i++ {
-			img.Set(i, y, borderColor)
-			img.Set(i, y+h-1, borderColor)
-		}
+		cv.fillRect(x, y, w, h, c)
+		cv.strokeRect(x, y, w, h, cv.pal.border)
 
-		for j := y; j < y+h; j++ {
-			img.Set(x, j, borderColor)
-			img.Set(x+w-1, j, borderColor)
-		}
-
-		face := basicfont.Face7x13
-		label := name
-		labelWidth := font.MeasureString(face, label).Ceil()
-		labelX := x + (w-labelWidth)/2
-		labelY := y + 18
-		col := color.RGBA{30, 30, 30, 255}
-		d := &font.Drawer{
-			Dst:  img,
-			Src:  image.NewUniform(col),
-			Face: face,
-			Dot:  fixed.P(labelX, labelY),
-		}
-		d.DrawString(label)
+		labelWidth := cv.measure(name)
+		cv.text(x+(w-labelWidth)/2, y+18, name, cv.pal.text)
 
 		filterCount := len(filterBar.Filters)
 		if filterCount > 0 {
@@ -186,138 +378,33 @@ func drawComponent(img *image.RGBA, x, y, w, h int, c color.Color, name string,
 			for i, f := range filterBar.Filters {
 				fx := x + 10 + i*filterW
 				fy := y + 25
-				var fc color.Color
-				switch f.(type) {
-				case *bussola.FilterDate:
-					fc = color.RGBA{200, 230, 255, 255}
-				case *bussola.FilterSelect:
-					fc = color.RGBA{220, 255, 200, 255}
-				case *bussola.FilterText:
-					fc = color.RGBA{255, 255, 200, 255}
-				case *bussola.FilterBool:
-					fc = color.RGBA{255, 220, 220, 255}
-				case *bussola.FilterSearch:
-					fc = color.RGBA{220, 200, 255, 255}
-				case *bussola.FilterCheckbox:
-					fc = color.RGBA{255, 240, 200, 255}
-				case *bussola.FilterRadio:
-					fc = color.RGBA{255, 200, 240, 255}
-				case *bussola.FilterMultiSelect:
-					fc = color.RGBA{240, 200, 255, 255}
-				case *bussola.FilterBar:
-					fc = color.RGBA{220, 220, 220, 255}
-				case *bussola.FilterNumber:
-					fc = color.RGBA{255, 255, 200, 255}
-				case *bussola.FilterRange:
-					fc = color.RGBA{200, 255, 200, 255}
-				case *bussola.FilterToggle:
-					fc = color.RGBA{255, 220, 200, 255}
-				case *bussola.FilterSlider:
-					fc = color.RGBA{200, 255, 220, 255}
-				case *bussola.FilterColor:
-					fc = color.RGBA{220, 220, 255, 255}
-				default:
-					fc = color.RGBA{240, 240, 240, 255}
-				}
-
-				for i2 := fx; i2 < fx+filterW-8; i2++ {
-					for j2 := fy; j2 < fy+filterH-8; j2++ {
-						img.Set(i2, j2, fc)
-					}
-				}
-
-				for i2 := fx; i2 < fx+filterW-8; i2++ {
-					img.Set(i2, fy, borderColor)
-					img.Set(i2, fy+filterH-9, borderColor)
-				}
-
-				for j2 := fy; j2 < fy+filterH-8; j2++ {
-					img.Set(fx, j2, borderColor)
-					img.Set(fx+filterW-9, j2, borderColor)
-				}
-
-				labelF := f.Render()["label"].(string)
-				labelFW := font.MeasureString(face, labelF).Ceil()
+
+				cv.fillRect(fx, fy, filterW-8, filterH-8, cv.pal.filterColor(f))
+				cv.strokeRect(fx, fy, filterW-8, filterH-8, cv.pal.border)
+
+				labelF, _ := f.Render()["label"].(string)
+				labelFW := cv.measure(labelF)
 				labelFX := fx + ((filterW-8)-labelFW)/2
 				labelFY := fy + (filterH-8)/2
-				d2 := &font.Drawer{
-					Dst:  img,
-					Src:  image.NewUniform(col),
-					Face: face,
-					Dot:  fixed.P(labelFX, labelFY),
-				}
-				d2.DrawString(labelF)
-
-				var typeF string
-				switch f.(type) {
-				case *bussola.FilterDate:
-					typeF = "date"
-				case *bussola.FilterSelect:
-					typeF = "select"
-				case *bussola.FilterText:
-					typeF = "text"
-				case *bussola.FilterBool:
-					typeF = "bool"
-				case *bussola.FilterSearch:
-					typeF = "search"
-				case *bussola.FilterCheckbox:
-					typeF = "checkbox"
-				case *bussola.FilterRadio:
-					typeF = "radio"
-				case *bussola.FilterMultiSelect:
-					typeF = "multi-select"
-				case *bussola.FilterBar:
-					typeF = "filter-bar"
-				case *bussola.FilterNumber:
-					typeF = "number"
-				case *bussola.FilterRange:
-					typeF = "range"
-				case *bussola.FilterToggle:
-					typeF = "toggle"
-				case *bussola.FilterSlider:
-					typeF = "slider"
-				case *bussola.FilterColor:
-					typeF = "color"
-				default:
-					typeF = ""
-				}
-
-				typeFW := font.MeasureString(face, typeF).Ceil()
+				cv.text(labelFX, labelFY, labelF, cv.pal.text)
+
+				typeF := getFilterName(f)
+				typeFW := cv.measure(typeF)
 				typeFX := fx + ((filterW-8)-typeFW)/2
-				typeFY := labelFY + 13
-				d3 := &font.Drawer{
-					Dst:  img,
-					Src:  image.NewUniform(color.RGBA{120, 120, 120, 255}),
-					Face: face,
-					Dot:  fixed.P(typeFX, typeFY),
-				}
-				d3.DrawString(typeF)
+				cv.text(typeFX, labelFY+13, typeF, cv.pal.muted)
 			}
 		}
 
 		return
 	}
 
-	// Draw filled rectangle
-	for i := x; i < x+w; i++ {
-		for j := y; j < y+h; j++ {
-			img.Set(i, j, c)
-		}
-	}
+	cv.fillRect(x, y, w, h, c)
+	cv.strokeRect(x, y, w, h, cv.pal.border)
 
-	// Draw border
-	borderColor := color.RGBA{100, 100, 100, 255}
-	for i := x; i < x+w; i++ {
-		img.Set(i, y, borderColor)
-		img.Set(i, y+h-1, borderColor)
-	}
-
-	for j := y; j < y+h; j++ {
-		img.Set(x, j, borderColor)
-		img.Set(x+w-1, j, borderColor)
+	if drawContent(cv, x, y, w, h, c, name, component) {
+		return
 	}
 
-	face := basicfont.Face7x13
 	var title string
 	switch c := component.(type) {
 	case *bussola.Indicator:
@@ -337,44 +424,21 @@ func drawComponent(img *image.RGBA, x, y, w, h int, c color.Color, name string,
 	}
 
 	if title != "" {
-		titleW := font.MeasureString(face, title).Ceil()
+		titleW := cv.measure(title)
 		titleX := x + (w-titleW)/2
 		titleY := y + (h-13)/2
-		dTitle := &font.Drawer{
-			Dst:  img,
-			Src:  image.NewUniform(color.RGBA{30, 30, 30, 255}),
-			Face: face,
-			Dot:  fixed.P(titleX, titleY),
-		}
-		dTitle.DrawString(title)
-
-		typeStr := name
-		if typeStr != "" {
-			typeW := font.MeasureString(face, typeStr).Ceil()
-			typeX := x + (w-typeW)/2
-			typeY := titleY + 13
-			dType := &font.Drawer{
-				Dst:  img,
-				Src:  image.NewUniform(color.RGBA{120, 120, 120, 255}),
-				Face: face,
-				Dot:  fixed.P(typeX, typeY),
-			}
-			dType.DrawString(typeStr)
+		cv.text(titleX, titleY, title, cv.pal.text)
+
+		if name != "" {
+			typeW := cv.measure(name)
+			cv.text(x+(w-typeW)/2, titleY+13, name, cv.pal.muted)
 		}
 	} else {
-		label := name
-		labelWidth := font.MeasureString(face, label).Ceil()
+		labelWidth := cv.measure(name)
 		labelHeight := 13 // height of Face7x13
 		labelX := x + (w-labelWidth)/2
 		labelY := y + (h+labelHeight)/2 - 4
-		col := color.RGBA{30, 30, 30, 255}
-		d := &font.Drawer{
-			Dst:  img,
-			Src:  image.NewUniform(col),
-			Face: face,
-			Dot:  fixed.P(labelX, labelY),
-		}
-		d.DrawString(label)
+		cv.text(labelX, labelY, name, cv.pal.text)
 	}
 }
 
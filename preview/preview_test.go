@@ -0,0 +1,122 @@
+package preview
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/isaqueveras/bussola"
+)
+
+// previewDashboard returns a 2x3 dashboard with an indicator and a chart
+func previewDashboard() *bussola.Dashboard {
+	chart := bussola.NewChart("revenue", bussola.ChartLine)
+	chart.AddSeries("2024", "", 1, 3, 2)
+
+	grid := bussola.NewGrid("grid", 2, 3)
+	grid.AddItem(bussola.NewIndicator("users"), 0, 0, 1, 1)
+	grid.AddItem(chart, 0, 1, 2, 2)
+	dashboard := bussola.NewDashboard("dashboard", "")
+	dashboard.SetLayout(grid)
+	return dashboard
+}
+
+func TestScatterWithFewerXValues(t *testing.T) {
+	chart := bussola.NewChart("scatter", bussola.ChartScatter)
+	chart.Series = []bussola.Series{{Name: "s", Data: []float64{1, 2, 3}, X: []float64{0, 1}}}
+
+	grid := bussola.NewGrid("grid", 1, 1)
+	grid.AddItem(chart, 0, 0, 1, 1)
+	dashboard := bussola.NewDashboard("dashboard", "")
+	dashboard.SetLayout(grid)
+
+	for _, format := range []Format{FormatPNG, FormatSVG} {
+		if err := Encode(dashboard, io.Discard, &Options{Format: format}); err != nil {
+			t.Errorf("Encode(%d) error = %v", format, err)
+		}
+	}
+}
+
+func TestGeneratePreviewFormat(t *testing.T) {
+	tests := []struct {
+		file  string
+		magic string
+	}{
+		{"preview.png", "\x89PNG"},
+		{"preview.PNG", "\x89PNG"},
+		{"preview.svg", "<svg"},
+		{"preview.jpg", "\xff\xd8\xff"},
+		{"preview", "\xff\xd8\xff"},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.file)
+		if err := GeneratePreview(previewDashboard(), path); err != nil {
+			t.Fatalf("GeneratePreview(%s) error = %v", tt.file, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(strings.TrimSpace(string(data)), tt.magic) {
+			t.Errorf("GeneratePreview(%s) wrote %q..., want %q", tt.file, data[:min(len(data), 8)], tt.magic)
+		}
+	}
+}
+
+func TestGeneratePreviewWithoutLayout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preview.png")
+	err := GeneratePreview(bussola.NewDashboard("dashboard", ""), path)
+	if !errors.Is(err, ErrNoLayout) {
+		t.Fatalf("GeneratePreview() error = %v, want ErrNoLayout", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("GeneratePreview() created %s for a dashboard without layout", path)
+	}
+}
+
+func TestEncodeJPEGQuality(t *testing.T) {
+	size := func(quality int) int {
+		var buf bytes.Buffer
+		if err := Encode(previewDashboard(), &buf, &Options{Quality: quality}); err != nil {
+			t.Fatalf("Encode(quality %d) error = %v", quality, err)
+		}
+		return buf.Len()
+	}
+	low, high := size(10), size(100)
+	if low >= high {
+		t.Errorf("quality 10 wrote %d bytes and quality 100 wrote %d, want fewer at the lower quality", low, high)
+	}
+	if out := size(0); out != size(90) {
+		t.Errorf("quality 0 wrote %d bytes, want the default quality 90", out)
+	}
+	if out := size(200); out != size(90) {
+		t.Errorf("quality 200 wrote %d bytes, want the default quality 90", out)
+	}
+}
+
+func TestImage(t *testing.T) {
+	if _, err := Image(bussola.NewDashboard("dashboard", ""), nil); !errors.Is(err, ErrNoLayout) {
+		t.Errorf("Image() error = %v, want ErrNoLayout", err)
+	}
+
+	dashboard := previewDashboard()
+	dashboard.SetTheme(&bussola.Theme{Background: "#102030"})
+	img, err := Image(dashboard, &Options{CellWidth: 100, CellHeight: 50})
+	if err != nil {
+		t.Fatalf("Image() error = %v", err)
+	}
+	// Three 100px columns and two 50px rows, 10px apart inside 15px padding
+	if b := img.Bounds(); b.Dx() != 350 || b.Dy() != 140 {
+		t.Errorf("Image() bounds = %v, want 350x140", b)
+	}
+	r, g, b, _ := img.At(0, 0).RGBA()
+	if got := (color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 255}); got != (color.RGBA{0x10, 0x20, 0x30, 255}) {
+		t.Errorf("Image() corner = %v, want the theme background", got)
+	}
+}
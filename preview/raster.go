@@ -0,0 +1,113 @@
+package preview
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// rasterCanvas draws onto an in-memory RGBA image
+type rasterCanvas struct {
+	img  *image.RGBA
+	face font.Face
+}
+
+func newRasterCanvas(width, height int, background color.Color, face font.Face) *rasterCanvas {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{background}, image.Point{}, draw.Src)
+	return &rasterCanvas{img: img, face: face}
+}
+
+func (r *rasterCanvas) fillRect(x, y, w, h int, c color.Color) {
+	draw.Draw(r.img, image.Rect(x, y, x+w, y+h), image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+func (r *rasterCanvas) strokeRect(x, y, w, h int, c color.Color) {
+	for i := x; i < x+w; i++ {
+		r.blend(i, y, c)
+		r.blend(i, y+h-1, c)
+	}
+
+	for j := y + 1; j < y+h-1; j++ {
+		r.blend(x, j, c)
+		r.blend(x+w-1, j, c)
+	}
+}
+
+func (r *rasterCanvas) line(x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	e := dx + dy
+	for {
+		r.blend(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e2 := 2 * e; e2 >= dy {
+			e += dy
+			x0 += sx
+		} else if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func (r *rasterCanvas) fillPolygon(points []image.Point, c color.Color) {
+	if len(points) < 3 {
+		return
+	}
+
+	b := r.img.Bounds()
+	z := vector.NewRasterizer(b.Dx(), b.Dy())
+	z.MoveTo(float32(points[0].X), float32(points[0].Y))
+	for _, p := range points[1:] {
+		z.LineTo(float32(p.X), float32(p.Y))
+	}
+	z.ClosePath()
+	z.Draw(r.img, b, image.NewUniform(c), image.Point{})
+}
+
+// blend draws a single pixel over the existing one, honoring transparency
+func (r *rasterCanvas) blend(x, y int, c color.Color) {
+	if _, _, _, a := c.RGBA(); a == 0xffff {
+		r.img.Set(x, y, c)
+		return
+	}
+	draw.Draw(r.img, image.Rect(x, y, x+1, y+1), image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+func (r *rasterCanvas) text(x, y int, s string, c color.Color) {
+	d := &font.Drawer{
+		Dst:  r.img,
+		Src:  image.NewUniform(c),
+		Face: r.face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
+
+func (r *rasterCanvas) measure(s string) int {
+	return font.MeasureString(r.face, s).Ceil()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+package preview
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/isaqueveras/bussola"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// GenerateSVG writes a vector preview of the dashboard layout to w. It draws
+// the same wireframe as GeneratePreview, but stays crisp at any scale.
+func GenerateSVG(dashboard *bussola.Dashboard, w io.Writer, opts *Options) error {
+	if dashboard.Layout == nil {
+		return ErrNoLayout
+	}
+
+	geo, err := dashboard.Geometry(opts.viewport(dashboard.Layout))
+	if err != nil {
+		return err
+	}
+
+	pal := newPalette(dashboard.Theme)
+	face := dashboardFace(dashboard, opts)
+	family, size := "monospace", 13.0
+	if face != basicfont.Face7x13 {
+		family, size = dashboard.Theme.FontFamily, opts.fontSize()
+		if size <= 0 {
+			size = defaultFontSize
+		}
+	}
+
+	width, height := canvasSize(geo)
+	cv := &svgCanvas{w: bufio.NewWriter(w), face: face}
+	cv.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="%s" font-size="%g">`+"\n", width, height, width, height, escape(family), size)
+	cv.fillRect(0, 0, width, height, pal.background)
+	drawDashboard(&painter{canvas: cv, pal: pal, opts: opts, geo: geo}, dashboard)
+	cv.printf("</svg>\n")
+
+	if cv.err != nil {
+		return cv.err
+	}
+	return cv.w.Flush()
+}
+
+// svgCanvas writes drawing operations as SVG elements. Text is measured with
+// the same face as the raster backend so both layouts match.
+type svgCanvas struct {
+	w    *bufio.Writer
+	face font.Face
+	err  error
+}
+
+func (s *svgCanvas) printf(format string, args ...any) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = fmt.Fprintf(s.w, format, args...)
+}
+
+func (s *svgCanvas) fillRect(x, y, w, h int, c color.Color) {
+	s.printf(`<rect x="%d" y="%d" width="%d" height="%d" fill="%s"%s/>`+"\n", x, y, w, h, hex(c), opacity("fill", c))
+}
+
+func (s *svgCanvas) strokeRect(x, y, w, h int, c color.Color) {
+	s.printf(`<rect x="%.1f" y="%.1f" width="%d" height="%d" fill="none" stroke="%s"%s/>`+"\n", float64(x)+0.5, float64(y)+0.5, w-1, h-1, hex(c), opacity("stroke", c))
+}
+
+func (s *svgCanvas) line(x0, y0, x1, y1 int, c color.Color) {
+	s.printf(`<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s"%s/>`+"\n", x0, y0, x1, y1, hex(c), opacity("stroke", c))
+}
+
+func (s *svgCanvas) fillPolygon(points []image.Point, c color.Color) {
+	if len(points) < 3 {
+		return
+	}
+
+	coords := make([]string, len(points))
+	for i, p := range points {
+		coords[i] = fmt.Sprintf("%d,%d", p.X, p.Y)
+	}
+	s.printf(`<polygon points="%s" fill="%s"%s/>`+"\n", strings.Join(coords, " "), hex(c), opacity("fill", c))
+}
+
+func (s *svgCanvas) text(x, y int, str string, c color.Color) {
+	if str == "" {
+		return
+	}
+	s.printf(`<text x="%d" y="%d" fill="%s" textLength="%d" lengthAdjust="spacingAndGlyphs">%s</text>`+"\n", x, y, hex(c), s.measure(str), escape(str))
+}
+
+func (s *svgCanvas) measure(str string) int {
+	return font.MeasureString(s.face, str).Ceil()
+}
+
+// escape makes s safe inside XML text and attributes. Characters XML cannot
+// hold, such as control codes in a title, become U+FFFD.
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func hex(c color.Color) string {
+	r, g, b, _ := color.NRGBAModel.Convert(c).RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+func opacity(attr string, c color.Color) string {
+	_, _, _, a := color.NRGBAModel.Convert(c).RGBA()
+	if a == 0xffff {
+		return ""
+	}
+	return fmt.Sprintf(` %s-opacity="%.3f"`, attr, float64(a)/0xffff)
+}
@@ -0,0 +1,61 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/isaqueveras/bussola"
+)
+
+func TestGenerateSVGWellFormed(t *testing.T) {
+	bar := bussola.NewFilterBar("filters")
+	bar.AddFilter(bussola.NewFilterDate("Period", "period"))
+	bar.AddFilter(bussola.NewFilterSelect("Status", "status", []string{"open", "closed"}))
+
+	title := `<Sales> & "Q1" 'EU'` + "\x01"
+	inner := bussola.NewGrid("inner", 1, 2)
+	inner.AddItem(bussola.NewIndicator(title), 0, 0, 1, 1)
+	inner.AddItem(bussola.NewIndicator("nested"), 0, 1, 1, 1)
+
+	grid := bussola.NewGrid("grid", 2, 2)
+	grid.AddItem(bar, 0, 0, 1, 2)
+	grid.AddItem(inner, 1, 0, 1, 2)
+	dashboard := bussola.NewDashboard("dashboard", "")
+	dashboard.SetLayout(grid)
+
+	var buf bytes.Buffer
+	if err := GenerateSVG(dashboard, &buf, &Options{CellWidth: 400}); err != nil {
+		t.Fatalf("GenerateSVG() error = %v", err)
+	}
+
+	dec := xml.NewDecoder(&buf)
+	var texts []string
+	var inText bool
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("GenerateSVG() wrote malformed XML: %v", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			inText = tok.Name.Local == "text"
+		case xml.CharData:
+			if inText {
+				texts = append(texts, string(tok))
+			}
+		case xml.EndElement:
+			inText = false
+		}
+	}
+	for _, want := range []string{`<Sales> & "Q1" 'EU'` + "\uFFFD", "nested", "Period", "date", "Status", "select"} {
+		if !slices.Contains(texts, want) {
+			t.Errorf("GenerateSVG() texts = %q, want %q", texts, want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package preview
+
+import (
+	"image/color"
+
+	"github.com/isaqueveras/bussola"
+)
+
+// palette holds the colors used to draw a preview. Dashboards without a
+// theme keep the classic per-type wireframe colors.
+type palette struct {
+	themed     bool
+	background color.Color
+	text       color.Color
+	muted      color.Color
+	border     color.Color
+	accent     color.Color
+	widget     color.Color
+	panel      color.Color
+	control    color.Color
+	slices     []color.Color
+}
+
+var defaultPalette = palette{
+	background: color.White,
+	text:       color.RGBA{30, 30, 30, 255},
+	muted:      color.RGBA{120, 120, 120, 255},
+	border:     color.RGBA{100, 100, 100, 255},
+	accent:     color.RGBA{25, 118, 210, 255},
+	slices: []color.Color{
+		color.RGBA{25, 118, 210, 255},
+		color.RGBA{255, 193, 7, 255},
+		color.RGBA{76, 175, 80, 255},
+		color.RGBA{233, 30, 99, 255},
+		color.RGBA{156, 39, 176, 255},
+		color.RGBA{255, 87, 34, 255},
+		color.RGBA{0, 188, 212, 255},
+	},
+}
+
+// newPalette derives the preview colors from the dashboard theme. Colors
+// that are missing or not valid hex fall back to the defaults.
+func newPalette(theme *bussola.Theme) palette {
+	p := defaultPalette
+	if theme == nil {
+		return p
+	}
+
+	parse := func(s string, fallback color.Color) color.Color {
+		if c, err := bussola.ParseHexColor(s); err == nil {
+			return c
+		}
+		return fallback
+	}
+
+	p.themed = true
+	p.background = parse(theme.Background, p.background)
+	p.text = parse(theme.TextColor, p.text)
+	p.accent = parse(theme.Primary, p.accent)
+	secondary := parse(theme.Secondary, p.muted)
+
+	p.muted = mix(p.text, p.background, 0.45)
+	p.border = mix(p.text, p.background, 0.6)
+	p.widget = mix(p.background, p.accent, 0.08)
+	p.panel = mix(p.background, secondary, 0.12)
+	p.control = mix(p.background, p.accent, 0.18)
+	p.slices = append([]color.Color{p.accent, secondary}, defaultPalette.slices[1:]...)
+	return p
+}
+
+// componentColor returns the fill used for a component
+func (p palette) componentColor(component bussola.Component) color.Color {
+	if !p.themed {
+		return getComponentColor(component)
+	}
+	if _, ok := component.(*bussola.FilterBar); ok {
+		return p.panel
+	}
+	return p.widget
+}
+
+// filterColor returns the fill used for a filter control
+func (p palette) filterColor(filter bussola.Filter) color.Color {
+	if !p.themed {
+		return getFilterColor(filter)
+	}
+	return p.control
+}
+
+// mix blends a towards b by the given weight of b
+func mix(a, b color.Color, weight float64) color.Color {
+	ar, ag, ab, _ := color.NRGBAModel.Convert(a).RGBA()
+	br, bg, bb, _ := color.NRGBAModel.Convert(b).RGBA()
+	blend := func(x, y uint32) uint8 {
+		return uint8((float64(x)*(1-weight) + float64(y)*weight) / 0x101)
+	}
+	return color.NRGBA{R: blend(ar, br), G: blend(ag, bg), B: blend(ab, bb), A: 255}
+}
@@ -0,0 +1,52 @@
+package preview
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/isaqueveras/bussola"
+)
+
+func rgb(c color.Color) color.NRGBA {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	n.A = 255
+	return n
+}
+
+func TestNewPalette(t *testing.T) {
+	if p := newPalette(nil); p.themed || rgb(p.background) != rgb(defaultPalette.background) {
+		t.Errorf("newPalette(nil) = %+v, want the default palette", p)
+	}
+
+	p := newPalette(&bussola.Theme{Primary: "#f00", Secondary: "#00ff00", Background: "#102030", TextColor: "#ffffffff"})
+	tests := []struct {
+		name string
+		got  color.Color
+		want color.NRGBA
+	}{
+		{"background", p.background, color.NRGBA{0x10, 0x20, 0x30, 255}},
+		{"text", p.text, color.NRGBA{0xff, 0xff, 0xff, 255}},
+		{"accent", p.accent, color.NRGBA{0xff, 0, 0, 255}},
+		{"first slice", p.slices[0], color.NRGBA{0xff, 0, 0, 255}},
+		{"second slice", p.slices[1], color.NRGBA{0, 0xff, 0, 255}},
+	}
+	for _, tt := range tests {
+		if got := rgb(tt.got); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if !p.themed || rgb(p.componentColor(bussola.NewIndicator("a"))) != rgb(p.widget) {
+		t.Error("themed palette does not fill widgets with its widget color")
+	}
+
+	fallback := newPalette(&bussola.Theme{Primary: "red", Background: "#12", TextColor: "#gggggg"})
+	for name, pair := range map[string][2]color.Color{
+		"background": {fallback.background, defaultPalette.background},
+		"text":       {fallback.text, defaultPalette.text},
+		"accent":     {fallback.accent, defaultPalette.accent},
+	} {
+		if rgb(pair[0]) != rgb(pair[1]) {
+			t.Errorf("invalid %s = %v, want the default %v", name, rgb(pair[0]), rgb(pair[1]))
+		}
+	}
+}
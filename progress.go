@@ -0,0 +1,104 @@
+package bussola
+
+import "math"
+
+// Threshold is a named band of a progress bar. A bar whose value is below
+// Below, and not below an earlier band, takes the band's color.
+type Threshold struct {
+	Name  string  `json:"name"`
+	Below float64 `json:"below"`
+	Color string  `json:"color"`
+}
+
+// Segment is one part of a stacked progress bar. Value is the amount the
+// segment adds, drawn after the previous segments.
+type Segment struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+	Color string  `json:"color"`
+}
+
+// AddThreshold appends a band applied to values below the given bound.
+// Bands are checked in the order they were added.
+func (p *ProgressBar) AddThreshold(name string, below float64, color string) {
+	p.Thresholds = append(p.Thresholds, Threshold{Name: name, Below: below, Color: color})
+}
+
+// AddSegment appends a part to a stacked progress bar
+func (p *ProgressBar) AddSegment(name string, value float64, color string) {
+	p.Segments = append(p.Segments, Segment{Name: name, Value: value, Color: color})
+}
+
+// SetTarget places a target marker on the bar
+func (p *ProgressBar) SetTarget(target float64) {
+	p.Target = &target
+}
+
+// Percent returns how far Value is between MinValue and MaxValue, clamped
+// to [0, 100]. An empty or invalid range gives 0.
+func (p *ProgressBar) Percent() float64 {
+	return p.percentOf(p.Value - p.MinValue)
+}
+
+// Band returns the threshold band of the current value, or nil
+func (p *ProgressBar) Band() *Threshold {
+	for i := range p.Thresholds {
+		if p.Value < p.Thresholds[i].Below {
+			return &p.Thresholds[i]
+		}
+	}
+	return nil
+}
+
+// percentOf converts an amount measured from MinValue to a clamped percent
+func (p *ProgressBar) percentOf(amount float64) float64 {
+	span := p.MaxValue - p.MinValue
+	if !(span > 0) || math.IsInf(span, 0) {
+		return 0
+	}
+	percent := amount / span * 100
+	if math.IsNaN(percent) {
+		return 0
+	}
+	return math.Max(0, math.Min(100, percent))
+}
+
+// Validate checks the range of the bar, its bands, segments and target
+func (p *ProgressBar) Validate() error {
+	v := &validator{}
+	if p.MaxValue <= p.MinValue {
+		v.add("maxValue", "must be greater than minValue %g, got %g", p.MinValue, p.MaxValue)
+	}
+
+	for i, t := range p.Thresholds {
+		field := joinPath("thresholds", indexPath(i))
+		if i > 0 && t.Below <= p.Thresholds[i-1].Below {
+			v.add(field+".below", "%g must be greater than the previous band", t.Below)
+		}
+		if _, err := ParseHexColor(t.Color); err != nil {
+			v.add(field+".color", "%v", err)
+		}
+	}
+
+	total := 0.0
+	for i, s := range p.Segments {
+		field := joinPath("segments", indexPath(i))
+		if s.Value < 0 {
+			v.add(field+".value", "must not be negative, got %g", s.Value)
+		}
+		if s.Color != "" {
+			if _, err := ParseHexColor(s.Color); err != nil {
+				v.add(field+".color", "%v", err)
+			}
+		}
+		total += s.Value
+	}
+	if p.MaxValue > p.MinValue && total > p.MaxValue-p.MinValue {
+		v.add("segments", "add up to %g, more than the range of %g", total, p.MaxValue-p.MinValue)
+	}
+
+	if t := p.Target; t != nil && (*t < p.MinValue || *t > p.MaxValue) {
+		v.add("target", "%g is outside [%g, %g]", *t, p.MinValue, p.MaxValue)
+	}
+	return v.err()
+}
@@ -0,0 +1,121 @@
+package bussola
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestProgressBarPercent(t *testing.T) {
+	tests := []struct {
+		name          string
+		min, max, val float64
+		want          float64
+	}{
+		{"middle", 0, 100, 25, 25},
+		{"offset range", 10, 110, 70, 60},
+		{"below range", 0, 100, -5, 0},
+		{"above range", 0, 100, 150, 100},
+		{"empty range", 0, 0, 5, 0},
+		{"inverted range", 100, 0, 50, 0},
+		{"infinite range", 0, math.Inf(1), 5, 0},
+		{"NaN value", 0, 100, math.NaN(), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProgressBar("bar")
+			p.MinValue, p.MaxValue, p.Value = tt.min, tt.max, tt.val
+			if got := p.Percent(); got != tt.want {
+				t.Errorf("Percent() = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgressBarThresholds(t *testing.T) {
+	p := NewProgressBar("bar")
+	p.AddThreshold("low", 30, "#E53935")
+	p.AddThreshold("mid", 70, "#FDD835")
+	p.AddThreshold("high", 101, "#43A047")
+
+	tests := []struct {
+		value float64
+		band  string
+	}{
+		{0, "low"},
+		{29.9, "low"},
+		{30, "mid"},
+		{100, "high"},
+		{101, ""},
+	}
+	for _, tt := range tests {
+		p.Value = tt.value
+		got := ""
+		if band := p.Band(); band != nil {
+			got = band.Name
+		}
+		if got != tt.band {
+			t.Errorf("Band() at %g = %q, want %q", tt.value, got, tt.band)
+		}
+
+		if rendered, _ := p.Render()["band"].(string); rendered != tt.band {
+			t.Errorf("Render() band at %g = %q, want %q", tt.value, rendered, tt.band)
+		}
+	}
+
+	p.Value = 50
+	if m := p.Render(); m["color"] != "#FDD835" {
+		t.Errorf("Render() color = %v, want the mid band color", m["color"])
+	}
+}
+
+func TestProgressBarSegmentsAndTarget(t *testing.T) {
+	p := NewProgressBar("bar")
+	p.MinValue, p.MaxValue = 10, 110
+	p.AddSegment("a", 20, "#1E88E5")
+	p.AddSegment("b", 30, "")
+	p.SetTarget(80)
+
+	m := p.Render()
+	segments := m["segments"].([]map[string]any)
+	if len(segments) != 2 || segments[0]["percent"] != 20.0 || segments[1]["percent"] != 30.0 {
+		t.Errorf("segments = %v, want 20%% and 30%%", segments)
+	}
+	if m["target"] != 80.0 || m["targetPercent"] != 70.0 {
+		t.Errorf("target = %v at %v%%, want 80 at 70%%", m["target"], m["targetPercent"])
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestProgressBarValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(p *ProgressBar)
+		field string
+	}{
+		{"empty range", func(p *ProgressBar) { p.MaxValue = 0 }, "maxValue"},
+		{"unordered bands", func(p *ProgressBar) {
+			p.AddThreshold("high", 70, "#43A047")
+			p.AddThreshold("low", 30, "#E53935")
+		}, "thresholds[1].below"},
+		{"band color", func(p *ProgressBar) { p.AddThreshold("low", 30, "red") }, "thresholds[0].color"},
+		{"negative segment", func(p *ProgressBar) { p.AddSegment("a", -1, "") }, "segments[0].value"},
+		{"segments past the range", func(p *ProgressBar) {
+			p.AddSegment("a", 60, "")
+			p.AddSegment("b", 50, "")
+		}, "segments"},
+		{"target outside", func(p *ProgressBar) { p.SetTarget(120) }, "target"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProgressBar("bar")
+			tt.edit(p)
+			var ve *ValidationError
+			if err := p.Validate(); !errors.As(err, &ve) || len(ve.Errors) != 1 || ve.Errors[0].Field != tt.field {
+				t.Fatalf("Validate() = %v, want one error on %s", err, tt.field)
+			}
+		})
+	}
+}
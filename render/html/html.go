@@ -0,0 +1,532 @@
+// Package html renders a dashboard as a single self-contained HTML page.
+// The page has inline styles and scripts only, so it works offline.
+package html
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/isaqueveras/bussola"
+)
+
+// Render writes the dashboard as a standalone HTML document to w
+func Render(w io.Writer, dashboard *bussola.Dashboard) error {
+	if dashboard == nil {
+		return fmt.Errorf("html: dashboard is nil")
+	}
+	return page.Execute(w, newPageView(dashboard))
+}
+
+type pageView struct {
+	Title       string
+	Description string
+	Theme       bussola.Theme
+	FontFamily  template.CSS
+	Surface     string // card background derived from Theme.Background
+	Layout      *gridView
+}
+
+type gridView struct {
+	Title   string
+	Rows    int
+	Columns int
+	Spacing float64
+	Padding float64
+	Nested  bool
+	Cells   []cellView
+}
+
+type cellView struct {
+	Row     int
+	Column  int
+	RowSpan int
+	ColSpan int
+	Kind    string
+	Widget  any
+	Error   string
+}
+
+type tableView struct {
+	*bussola.Table
+	Columns []bussola.Column
+	Rows    [][]cellValue
+	Pages   int
+}
+
+type cellValue struct {
+	Text  string
+	Align bussola.Align
+	Link  string
+}
+
+type chartView struct {
+	*bussola.Chart
+	Kind   string
+	Lines  []lineView
+	Bars   []rectView
+	Slices []sliceView
+	Donut  bool
+	Legend []legendView
+	Empty  bool
+}
+
+type lineView struct {
+	Points string
+	Area   string
+	Color  string
+	Dots   []pointView
+	Line   bool
+}
+
+type pointView struct {
+	X, Y float64
+}
+
+type rectView struct {
+	X, Y, W, H float64
+	Color      string
+}
+
+type sliceView struct {
+	Path  string
+	Color string
+}
+
+type legendView struct {
+	Name  string
+	Color string
+}
+
+type progressView struct {
+	*bussola.ProgressBar
+	Percent   float64
+	Color     string
+	Band      string
+	Segments  []segmentView
+	HasTarget bool
+	Target    float64 // marker position in percent
+}
+
+type segmentView struct {
+	Name    string
+	Color   string
+	Start   float64
+	Percent float64
+}
+
+func newPageView(d *bussola.Dashboard) pageView {
+	theme := bussola.Theme{
+		Primary:    "#1976D2",
+		Secondary:  "#424242",
+		Background: "#FFFFFF",
+		TextColor:  "#212121",
+		FontFamily: defaultFontFamily,
+	}
+	if d.Theme != nil {
+		theme = *d.Theme
+	}
+
+	view := pageView{
+		Title:       d.Title,
+		Description: d.Description,
+		Theme:       theme,
+		FontFamily:  fontFamily(theme.FontFamily),
+		Surface:     surfaceColor(theme.Background),
+	}
+	if d.Layout != nil {
+		view.Layout = newGridView(d.Layout, false)
+	}
+	return view
+}
+
+const defaultFontFamily = "Roboto, sans-serif"
+
+// fontFamily checks a CSS font-family list and returns it as trusted CSS,
+// since html/template rejects quoted family names. Each family is either a
+// quoted name or plain words; lists with anything else fall back to the
+// default family.
+func fontFamily(list string) template.CSS {
+	families := strings.Split(list, ",")
+	for i, family := range families {
+		family = strings.TrimSpace(family)
+		if n := len(family); n >= 2 && (family[0] == '"' || family[0] == '\'') && family[n-1] == family[0] {
+			name := family[1 : n-1]
+			if name == "" || strings.ContainsAny(name, "\"'\\<>\n\r\f") {
+				return defaultFontFamily
+			}
+			families[i] = `"` + name + `"`
+			continue
+		}
+		if family == "" || strings.IndexFunc(family, func(r rune) bool {
+			return !(r == ' ' || r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+		}) >= 0 {
+			return defaultFontFamily
+		}
+		families[i] = family
+	}
+	return template.CSS(strings.Join(families, ", "))
+}
+
+// surfaceColor returns the card background for a page background: white on
+// light pages and the background lifted slightly toward white on dark ones,
+// so card text in the theme TextColor stays readable
+func surfaceColor(background string) string {
+	c, err := bussola.ParseHexColor(background)
+	if err != nil {
+		return "#FFFFFF"
+	}
+	if 0.299*float64(c.R)+0.587*float64(c.G)+0.114*float64(c.B) >= 128 {
+		return "#FFFFFF"
+	}
+	lift := func(v uint8) uint8 { return v + uint8(float64(255-v)*0.08) }
+	return fmt.Sprintf("#%02X%02X%02X", lift(c.R), lift(c.G), lift(c.B))
+}
+
+func newGridView(g *bussola.Grid, nested bool) *gridView {
+	view := &gridView{
+		Title:   g.Title,
+		Rows:    g.Rows,
+		Columns: g.Columns,
+		Spacing: g.Spacing,
+		Padding: g.Padding,
+		Nested:  nested,
+	}
+
+	for i := range g.Cells {
+		for j := range g.Cells[i] {
+			cell := g.Cells[i][j]
+			if cell == nil || cell.Content == nil {
+				continue
+			}
+			if o, ok := cell.Content.(bussola.CanvasObject); ok && !o.Visible() {
+				continue
+			}
+			view.Cells = append(view.Cells, newCellView(cell))
+		}
+	}
+	return view
+}
+
+func newCellView(cell *bussola.GridCell) cellView {
+	view := cellView{
+		Row:     cell.Row,
+		Column:  cell.Column,
+		RowSpan: max(cell.RowSpan, 1),
+		ColSpan: max(cell.ColSpan, 1),
+	}
+
+	switch c := cell.Content.(type) {
+	case *bussola.Grid:
+		view.Kind, view.Widget = "grid", newGridView(c, true)
+	case *bussola.Indicator:
+		view.Kind, view.Widget = "indicator", c
+	case *bussola.Chart:
+		view.Kind, view.Widget = "chart", newChartView(c)
+	case *bussola.Table:
+		view.Kind, view.Widget = "table", newTableView(c)
+	case *bussola.ProgressBar:
+		view.Kind, view.Widget = "progressBar", newProgressView(c)
+	case *bussola.FilterBar:
+		view.Kind, view.Widget = "filterBar", c
+	case *bussola.Ranking:
+		view.Kind, view.Widget = "ranking", c
+	default:
+		view.Kind = "unknown"
+	}
+
+	if w, ok := cell.Content.(interface{ Err() error }); ok && w.Err() != nil && view.Kind != "grid" {
+		view.Error = w.Err().Error()
+	}
+	return view
+}
+
+func newTableView(t *bussola.Table) tableView {
+	view := tableView{Table: t, Columns: t.VisibleColumns(), Pages: 1}
+
+	// In-memory tables are paged by the page script; tables with a Source
+	// only hold the page they loaded
+	rows := t.CurrentRows()
+	if t.Source == nil {
+		rows = t.SortedData()
+		view.Pages = t.TotalPages()
+	}
+
+	for _, row := range rows {
+		values := make([]cellValue, len(view.Columns))
+		for i, col := range view.Columns {
+			v := col.Value(row)
+			values[i] = cellValue{Text: col.FormatValue(v), Align: col.Align}
+			if v == nil {
+				values[i].Text = "—"
+			}
+			if link, ok := v.(string); ok && col.Type == bussola.ColumnLink {
+				values[i].Link = link
+			}
+		}
+		view.Rows = append(view.Rows, values)
+	}
+	return view
+}
+
+func newProgressView(p *bussola.ProgressBar) progressView {
+	view := progressView{ProgressBar: p, Percent: p.Percent()}
+	if band := p.Band(); band != nil {
+		view.Band = band.Name
+		if _, err := bussola.ParseHexColor(band.Color); err == nil {
+			view.Color = band.Color
+		}
+	}
+
+	span := p.MaxValue - p.MinValue
+	if span > 0 {
+		done := 0.0
+		for i, s := range p.Segments {
+			start := math.Min(100, done/span*100)
+			done += math.Max(0, s.Value)
+			end := math.Min(100, done/span*100)
+			color := s.Color
+			if _, err := bussola.ParseHexColor(color); err != nil {
+				color = seriesColors[i%len(seriesColors)]
+			}
+			view.Segments = append(view.Segments, segmentView{Name: s.Name, Color: color, Start: start, Percent: end - start})
+		}
+		if p.Target != nil {
+			view.HasTarget = true
+			view.Target = math.Max(0, math.Min(100, (*p.Target-p.MinValue)/span*100))
+		}
+	}
+	return view
+}
+
+// chart drawings use a 300x150 view box
+const (
+	chartWidth  = 300.0
+	chartHeight = 150.0
+)
+
+var seriesColors = []string{"#1976D2", "#FFC107", "#4CAF50", "#E91E63", "#9C27B0", "#FF5722", "#00BCD4"}
+
+func newChartView(c *bussola.Chart) chartView {
+	n := c.Normalized()
+	view := chartView{Chart: c, Kind: "line"}
+	if len(n.Series) == 0 {
+		view.Empty = true
+		return view
+	}
+
+	colors := make([]string, len(n.Series))
+	for i, s := range n.Series {
+		colors[i] = s.Color
+		if colors[i] == "" {
+			colors[i] = seriesColors[i%len(seriesColors)]
+		}
+		if len(n.Series) > 1 {
+			view.Legend = append(view.Legend, legendView{Name: s.Name, Color: colors[i]})
+		}
+	}
+
+	switch {
+	case n.Type.Circular():
+		view.Kind = "pie"
+		view.Donut = n.Type == bussola.ChartDonut
+		view.Slices = pieSlices(n.Series[0].Data)
+	case n.Type == bussola.ChartBar:
+		view.Kind = "bar"
+		view.Bars = bars(n, colors)
+	default:
+		view.Lines = lines(n, colors)
+	}
+	return view
+}
+
+// scale returns the largest value drawn, summing series when stacked
+func scale(n *bussola.Chart) float64 {
+	peak := 0.0
+	if n.YAxis != nil && n.YAxis.Max != nil {
+		return *n.YAxis.Max
+	}
+	stack := map[int]float64{}
+	for _, s := range n.Series {
+		for i, v := range s.Data {
+			v = math.Max(v, 0)
+			if n.Stacked {
+				stack[i] += v
+				v = stack[i]
+			}
+			peak = math.Max(peak, v)
+		}
+	}
+	if peak == 0 {
+		return 1
+	}
+	return peak
+}
+
+func bars(n *bussola.Chart, colors []string) []rectView {
+	peak, points := scale(n), 0
+	for _, s := range n.Series {
+		points = max(points, len(s.Data))
+	}
+	if points == 0 {
+		return nil
+	}
+
+	slot := chartWidth / float64(points)
+	width := slot * 0.7
+	if !n.Stacked {
+		width /= float64(len(n.Series))
+	}
+
+	out := []rectView{}
+	base := make([]float64, points)
+	for si, s := range n.Series {
+		for i, v := range s.Data {
+			h := math.Max(v, 0) / peak * (chartHeight - 10)
+			x := float64(i)*slot + slot*0.15
+			y := chartHeight - h
+			if n.Stacked {
+				y -= base[i]
+				base[i] += h
+			} else {
+				x += float64(si) * width
+			}
+			out = append(out, rectView{X: x, Y: y, W: width, H: h, Color: colors[si]})
+		}
+	}
+	return out
+}
+
+func lines(n *bussola.Chart, colors []string) []lineView {
+	peak := scale(n)
+	out := []lineView{}
+	base := map[int]float64{}
+	for si, s := range n.Series {
+		// Points without an X value cannot be placed
+		data := s.Data
+		if s.X != nil {
+			data = data[:min(len(data), len(s.X))]
+		}
+		step := chartWidth
+		if len(data) > 1 {
+			step = chartWidth / float64(len(data)-1)
+		}
+		xmin, xmax := span(s.X)
+
+		points := make([]string, len(data))
+		dots := []pointView{}
+		for i, v := range data {
+			v = math.Max(v, 0)
+			if n.Stacked {
+				base[i] += v
+				v = base[i]
+			}
+			x := float64(i) * step
+			if s.X != nil {
+				x = (s.X[i] - xmin) / (xmax - xmin) * chartWidth
+			}
+			y := chartHeight - 5 - v/peak*(chartHeight-10)
+			points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+			dots = append(dots, pointView{X: x, Y: y})
+		}
+
+		line := lineView{
+			Points: strings.Join(points, " "),
+			Color:  colors[si],
+			Line:   n.Type != bussola.ChartScatter,
+		}
+		if n.Type == bussola.ChartScatter {
+			line.Dots = dots
+		}
+		if n.Type == bussola.ChartArea && len(points) > 0 {
+			line.Area = fmt.Sprintf("0,%.1f %s %.1f,%.1f", chartHeight, line.Points, chartWidth, chartHeight)
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func span(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 1
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if lo == hi {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+func pieSlices(values []float64) []sliceView {
+	total := 0.0
+	for _, v := range values {
+		total += math.Max(v, 0)
+	}
+	if total == 0 {
+		return nil
+	}
+
+	const cx, cy, r = chartWidth / 2, chartHeight / 2, chartHeight/2 - 5
+	slices := []sliceView{}
+	angle := -math.Pi / 2
+	for i, v := range values {
+		if v <= 0 {
+			continue
+		}
+		sweep := v / total * 2 * math.Pi
+		large := 0
+		if sweep > math.Pi {
+			large = 1
+		}
+		x0, y0 := cx+r*math.Cos(angle), cy+r*math.Sin(angle)
+		x1, y1 := cx+r*math.Cos(angle+sweep-1e-6), cy+r*math.Sin(angle+sweep-1e-6)
+		slices = append(slices, sliceView{
+			Path:  fmt.Sprintf("M%.1f,%.1f L%.1f,%.1f A%.1f,%.1f 0 %d 1 %.1f,%.1f Z", cx, cy, x0, y0, r, r, large, x1, y1),
+			Color: seriesColors[i%len(seriesColors)],
+		})
+		angle += sweep
+	}
+	return slices
+}
+
+var funcs = template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+	"add": func(a, b int) int { return a + b },
+	"page": func(i, size int) int {
+		if size <= 0 {
+			return 1
+		}
+		return i/size + 1
+	},
+	"trend": func(t float64) string {
+		switch {
+		case t > 0:
+			return "up"
+		case t < 0:
+			return "down"
+		}
+		return "flat"
+	},
+	"arrow": func(t float64) string {
+		switch {
+		case t > 0:
+			return "▲"
+		case t < 0:
+			return "▼"
+		}
+		return "■"
+	},
+	"value": func(v any) string {
+		if v == nil {
+			return "—"
+		}
+		return fmt.Sprint(v)
+	},
+}
+
+var page = template.Must(template.New("page").Funcs(funcs).Parse(pageTemplate))
@@ -0,0 +1,201 @@
+package html
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/isaqueveras/bussola"
+)
+
+func TestRenderScatterWithFewerXValues(t *testing.T) {
+	chart := bussola.NewChart("scatter", bussola.ChartScatter)
+	chart.Series = []bussola.Series{{Name: "s", Data: []float64{1, 2, 3}, X: []float64{0, 1}}}
+
+	grid := bussola.NewGrid("grid", 1, 1)
+	grid.AddItem(chart, 0, 0, 1, 1)
+	dashboard := bussola.NewDashboard("dashboard", "")
+	dashboard.SetLayout(grid)
+
+	if err := Render(io.Discard, dashboard); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+}
+
+func renderPage(t *testing.T, d *bussola.Dashboard) string {
+	t.Helper()
+	var buf strings.Builder
+	if err := Render(&buf, d); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestRenderFontFamily(t *testing.T) {
+	tests := []struct {
+		family string
+		want   string
+	}{
+		{`"Open Sans", sans-serif`, `font-family: "Open Sans", sans-serif;`},
+		{`'Fira Code',monospace`, `font-family: "Fira Code", monospace;`},
+		{`Inter, sans-serif`, `font-family: Inter, sans-serif;`},
+		{`x; } body { display: none`, `font-family: Roboto, sans-serif;`},
+		{`"a</style>", serif`, `font-family: Roboto, sans-serif;`},
+		{`"unterminated, serif`, `font-family: Roboto, sans-serif;`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.family, func(t *testing.T) {
+			d := bussola.NewDashboard("dashboard", "")
+			d.Theme.FontFamily = tt.family
+			page := renderPage(t, d)
+			if !strings.Contains(page, tt.want) {
+				t.Errorf("page has no %q", tt.want)
+			}
+			if strings.Contains(page, "ZgotmplZ") {
+				t.Error("page has a value rejected by html/template")
+			}
+		})
+	}
+}
+
+func TestRenderDarkThemeSurface(t *testing.T) {
+	chart := bussola.NewChart("share", bussola.ChartDonut)
+	chart.AddSeries("share", "", 1, 2)
+	grid := bussola.NewGrid("grid", 1, 1)
+	grid.AddItem(chart, 0, 0, 1, 1)
+	d := bussola.NewDashboard("dashboard", "")
+	d.SetLayout(grid)
+	d.Theme.Background, d.Theme.TextColor = "#000000", "#FFFFFF"
+
+	page := renderPage(t, d)
+	for _, want := range []string{"--surface: #141414;", ".widget { height: 100%; background: var(--surface);", `<circle class="hole"`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page has no %q", want)
+		}
+	}
+	if strings.Contains(page, `fill="#fff"`) {
+		t.Error("page still paints the donut hole white")
+	}
+
+	d.Theme.Background = "#F5F5F5"
+	if page := renderPage(t, d); !strings.Contains(page, "--surface: #FFFFFF;") {
+		t.Error("light page has no white surface")
+	}
+}
+
+func TestRenderGridTemplateAndSpans(t *testing.T) {
+	nested := bussola.NewGrid("Nested", 1, 2)
+	nested.AddItem(bussola.NewIndicator("inner"), 0, 1, 1, 1)
+	grid := bussola.NewGrid("Main", 3, 4)
+	grid.Spacing, grid.Padding = 8, 12
+	grid.AddItem(bussola.NewIndicator("wide"), 0, 0, 1, 4)
+	grid.AddItem(bussola.NewIndicator("tall"), 1, 1, 2, 1)
+	grid.AddItem(nested, 1, 2, 1, 2)
+	d := bussola.NewDashboard("dashboard", "")
+	d.SetLayout(grid)
+
+	page := renderPage(t, d)
+	for _, want := range []string{
+		`<div class="grid" style="grid-template-columns: repeat(4, minmax(0, 1fr)); grid-template-rows: repeat(3, auto); gap: 8px; padding: 12px;">`,
+		`<div class="cell" style="grid-row: 1 / span 1; grid-column: 1 / span 4;">`,
+		`<div class="cell" style="grid-row: 2 / span 2; grid-column: 2 / span 1;">`,
+		`<div class="cell" style="grid-row: 2 / span 1; grid-column: 3 / span 2;">`,
+		// Nested grids put their title in an extra first row
+		`<div class="grid" style="grid-template-columns: repeat(2, minmax(0, 1fr)); grid-template-rows: auto repeat(1, auto); gap: 10px; padding: 15px;"><h3 class="grid-title">Nested</h3>`,
+		`<div class="cell" style="grid-row: 2 / span 1; grid-column: 2 / span 1;">`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page has no %s", want)
+		}
+	}
+}
+
+func TestRenderThemeVariables(t *testing.T) {
+	d := bussola.NewDashboard("dashboard", "")
+	d.SetTheme(&bussola.Theme{Primary: "#112233", Secondary: "#445566", Background: "#F0F0F0", TextColor: "#101010", FontFamily: "Inter"})
+
+	page := renderPage(t, d)
+	for _, want := range []string{
+		"--primary: #112233;",
+		"--secondary: #445566;",
+		"--background: #F0F0F0;",
+		"--text: #101010;",
+		"font-family: Inter;",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page has no %s", want)
+		}
+	}
+
+	d.Theme = nil
+	if page := renderPage(t, d); !strings.Contains(page, "--primary: #1976D2;") || !strings.Contains(page, "font-family: Roboto, sans-serif;") {
+		t.Error("page without a theme does not use the default theme")
+	}
+}
+
+func TestRenderTablePager(t *testing.T) {
+	table := bussola.NewTable("Users", []string{"ID"})
+	table.PageSize = 2
+	table.CurrentPage = 2
+	for i := range 5 {
+		table.Data = append(table.Data, map[string]any{"id": i})
+	}
+	grid := bussola.NewGrid("grid", 1, 1)
+	grid.AddItem(table, 0, 0, 1, 1)
+	d := bussola.NewDashboard("dashboard", "")
+	d.SetLayout(grid)
+
+	page := renderPage(t, d)
+	for _, want := range []string{
+		`<table data-pager data-pages="3" data-page="2">`,
+		`<tr data-page="1"><td class="left">1</td></tr>`,
+		`<tr data-page="2"><td class="left">2</td></tr>`,
+		`<tr data-page="3"><td class="left">4</td></tr>`,
+		`<div class="pager"><button type="button" data-step="-1">‹</button><span>2 / 3</span><button type="button" data-step="1">›</button></div>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page has no %s", want)
+		}
+	}
+
+	table.PageSize = 10
+	if page := renderPage(t, d); strings.Contains(page, `<div class="pager">`) {
+		t.Error("single page table has a pager")
+	}
+}
+
+func TestRenderFilterControls(t *testing.T) {
+	tests := []struct {
+		filter bussola.Filter
+		want   string
+	}{
+		{bussola.NewFilterDate("Period", "period"), `<input type="date" name="period">`},
+		{bussola.NewFilterSelect("Type", "type", []string{"a", "b"}), `<select name="type"><option>a</option><option>b</option></select>`},
+		{bussola.NewFilterMultiSelect("Tags", "tags", []string{"x"}), `<select name="tags" multiple><option>x</option></select>`},
+		{bussola.NewFilterCheckbox("Flags", "flags", []string{"p"}), `<fieldset><label><input type="checkbox" name="flags" value="p"> p</label></fieldset>`},
+		{bussola.NewFilterRadio("Mode", "mode", []string{"m"}), `<fieldset><label><input type="radio" name="mode" value="m"> m</label></fieldset>`},
+		{bussola.NewFilterBool("Active", "active"), `<input type="checkbox" name="active">`},
+		{bussola.NewFilterToggle("Live", "live"), `<input type="checkbox" name="live">`},
+		{bussola.NewFilterNumber("Count", "count", 1, 9), `<input type="number" name="count" min="1" max="9">`},
+		{bussola.NewFilterRange("Amount", "amount", 0, 100), `<span><input type="number" name="amount_from" min="0" max="100" placeholder="0"> – <input type="number" name="amount_to" min="0" max="100" placeholder="100"></span>`},
+		{bussola.NewFilterSlider("Level", "level", 0, 10, 5), `<input type="range" name="level" min="0" max="10" value="5">`},
+		{bussola.NewFilterSearch("Search", "q", "Find"), `<input type="search" name="q" placeholder="Find">`},
+		{bussola.NewFilterColor("Color", "color", "#ff0000"), `<input type="color" name="color" value="#ff0000">`},
+		{bussola.NewFilterText("Name", "name"), `<input type="text" name="name">`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			bar := bussola.NewFilterBar("Filters")
+			bar.AddFilter(tt.filter)
+			grid := bussola.NewGrid("grid", 1, 1)
+			grid.AddItem(bar, 0, 0, 1, 1)
+			d := bussola.NewDashboard("dashboard", "")
+			d.SetLayout(grid)
+
+			page := renderPage(t, d)
+			if !strings.Contains(page, `<label class="filter">`) || !strings.Contains(page, tt.want) {
+				t.Errorf("page has no %s", tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package html
+
+const pageTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+:root {
+  --primary: {{.Theme.Primary}};
+  --secondary: {{.Theme.Secondary}};
+  --background: {{.Theme.Background}};
+  --text: {{.Theme.TextColor}};
+  --surface: {{.Surface}};
+}
+* { box-sizing: border-box; }
+body { margin: 0; padding: 24px; background: var(--background); color: var(--text); font-family: {{.FontFamily}}; }
+header h1 { margin: 0 0 4px; font-size: 1.6em; }
+header p { margin: 0 0 16px; opacity: .7; }
+.grid { display: grid; }
+.grid > .grid-title { grid-column: 1 / -1; font-weight: 600; margin: 0; font-size: 1em; }
+.cell { min-width: 0; }
+.widget { height: 100%; background: var(--surface); border: 1px solid rgba(0,0,0,.12); border-radius: 8px; padding: 12px 16px; overflow: auto; }
+.widget h2 { margin: 0 0 8px; font-size: 1em; font-weight: 600; }
+.widget .subtitle, .widget .description { margin: 0 0 8px; font-size: .85em; opacity: .7; }
+.indicator .value { font-size: 2em; font-weight: 700; color: var(--primary); }
+.indicator .unit { font-size: .5em; font-weight: 400; margin-left: 4px; color: var(--text); }
+.trend { font-size: .85em; }
+.trend.up { color: #2E7D32; }
+.trend.down { color: #C62828; }
+.trend.flat { opacity: .6; }
+.progress .track { position: relative; height: 12px; background: rgba(0,0,0,.08); border-radius: 6px; overflow: hidden; }
+.progress .fill { height: 100%; background: var(--primary); }
+.progress .segment { position: absolute; top: 0; height: 100%; }
+.progress .target { position: absolute; top: 0; width: 2px; height: 100%; margin-left: -1px; background: var(--text); }
+.progress .percent { font-size: .85em; margin-top: 4px; }
+table { width: 100%; border-collapse: collapse; font-size: .9em; }
+th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid rgba(0,0,0,.08); }
+th { color: var(--secondary); }
+th.center, td.center { text-align: center; }
+th.right, td.right { text-align: right; }
+.pager { display: flex; gap: 8px; align-items: center; justify-content: flex-end; margin-top: 8px; font-size: .85em; }
+.pager button { border: 1px solid rgba(0,0,0,.2); background: none; border-radius: 4px; padding: 2px 8px; cursor: pointer; color: inherit; }
+.ranking ol { list-style: none; margin: 0; padding: 0; }
+.ranking li { display: flex; gap: 10px; align-items: center; padding: 6px 0; border-bottom: 1px solid rgba(0,0,0,.06); }
+.ranking .badge { flex: none; width: 28px; height: 28px; border-radius: 50%; background: var(--primary); color: #fff; display: flex; align-items: center; justify-content: center; font-weight: 700; font-size: .85em; }
+.ranking .item-description { display: block; font-size: .8em; opacity: .7; }
+.filters { display: flex; flex-wrap: wrap; gap: 12px; }
+.filter { display: flex; flex-direction: column; gap: 4px; font-size: .85em; min-width: 140px; }
+.filter fieldset { border: none; padding: 0; margin: 0; display: flex; gap: 8px; flex-wrap: wrap; }
+.filter input, .filter select { font: inherit; padding: 4px 6px; }
+.chart svg { width: 100%; height: auto; display: block; }
+.chart polyline { fill: none; stroke-width: 2; }
+.chart .area { opacity: .25; }
+.chart .hole { fill: var(--surface); }
+.chart .legend { display: flex; gap: 12px; flex-wrap: wrap; font-size: .8em; margin-top: 6px; }
+.chart .swatch { display: inline-block; width: 10px; height: 10px; border-radius: 2px; margin-right: 4px; }
+.empty { opacity: .6; font-size: .85em; }
+.failed { border-color: #C62828; }
+.error { color: #C62828; font-size: .85em; margin: 0; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+</header>
+{{with .Layout}}{{template "grid" .}}{{end}}
+<script>
+document.querySelectorAll("[data-pager]").forEach(function (table) {
+  var rows = table.querySelectorAll("tbody tr");
+  var pages = parseInt(table.dataset.pages, 10) || 1;
+  var page = Math.min(parseInt(table.dataset.page, 10) || 1, pages);
+  var label = table.parentNode.querySelector(".pager span");
+  function show() {
+    rows.forEach(function (row) { row.hidden = row.dataset.page !== String(page); });
+    if (label) { label.textContent = page + " / " + pages; }
+  }
+  table.parentNode.querySelectorAll(".pager button").forEach(function (button) {
+    button.addEventListener("click", function () {
+      page = Math.max(1, Math.min(pages, page + parseInt(button.dataset.step, 10)));
+      show();
+    });
+  });
+  show();
+});
+</script>
+</body>
+</html>
+{{define "grid"}}<div class="grid" style="grid-template-columns: repeat({{.Columns}}, minmax(0, 1fr)); grid-template-rows: {{if and .Nested .Title}}auto {{end}}repeat({{.Rows}}, auto); gap: {{.Spacing}}px; padding: {{.Padding}}px;">
+{{- $offset := 1}}{{if and .Nested .Title}}{{$offset = 2}}<h3 class="grid-title">{{.Title}}</h3>{{end}}
+{{- range .Cells}}
+<div class="cell" style="grid-row: {{add .Row $offset}} / span {{.RowSpan}}; grid-column: {{inc .Column}} / span {{.ColSpan}};">
+{{- if .Error}}<section class="widget failed"><h2>{{with .Widget}}{{.Title}}{{end}}</h2><p class="error">{{.Error}}</p></section>
+{{- else if eq .Kind "grid"}}{{template "grid" .Widget}}
+{{- else if eq .Kind "indicator"}}{{template "indicator" .Widget}}
+{{- else if eq .Kind "chart"}}{{template "chart" .Widget}}
+{{- else if eq .Kind "table"}}{{template "table" .Widget}}
+{{- else if eq .Kind "progressBar"}}{{template "progressBar" .Widget}}
+{{- else if eq .Kind "filterBar"}}{{template "filterBar" .Widget}}
+{{- else if eq .Kind "ranking"}}{{template "ranking" .Widget}}
+{{- else}}<div class="widget"></div>{{end}}
+</div>
+{{- end}}
+</div>{{end}}
+
+{{define "indicator"}}<section class="widget indicator">
+<h2>{{.Title}}</h2>
+<div class="value">{{value .Value}}{{if .Unit}}<span class="unit">{{.Unit}}</span>{{end}}</div>
+{{if .Trend}}<div class="trend {{trend .Trend}}">{{arrow .Trend}} {{.Trend}}%</div>{{end}}
+{{if .Description}}<p class="description">{{.Description}}</p>{{end}}
+</section>{{end}}
+
+{{define "chart"}}<section class="widget chart">
+<h2>{{.Title}}</h2>
+{{if .Subtitle}}<p class="subtitle">{{.Subtitle}}</p>{{end}}
+{{if .Empty}}<p class="empty">No data</p>{{else}}<svg viewBox="0 0 300 150" preserveAspectRatio="{{if eq .Kind "pie"}}xMidYMid meet{{else}}none{{end}}" role="img" aria-label="{{.Title}}">
+{{- if eq .Kind "pie"}}{{range .Slices}}<path d="{{.Path}}" fill="{{.Color}}"/>{{end}}{{if .Donut}}<circle class="hole" cx="150" cy="75" r="35"/>{{end}}
+{{- else if eq .Kind "bar"}}{{range .Bars}}<rect x="{{.X}}" y="{{.Y}}" width="{{.W}}" height="{{.H}}" fill="{{.Color}}"/>{{end}}
+{{- else}}{{range .Lines}}{{if .Area}}<polygon class="area" points="{{.Area}}" fill="{{.Color}}"/>{{end}}{{if .Line}}<polyline points="{{.Points}}" stroke="{{.Color}}"/>{{end}}{{$color := .Color}}{{range .Dots}}<circle cx="{{printf "%.1f" .X}}" cy="{{printf "%.1f" .Y}}" r="3" fill="{{$color}}"/>{{end}}{{end}}{{end}}
+</svg>
+{{- if .Legend}}<div class="legend">{{range .Legend}}<span><span class="swatch" style="background: {{.Color}};"></span>{{.Name}}</span>{{end}}</div>{{end}}{{end}}
+</section>{{end}}
+
+{{define "table"}}<section class="widget table">
+<h2>{{.Title}}</h2>
+<table data-pager data-pages="{{.Pages}}" data-page="{{.CurrentPage}}">
+<thead><tr>{{range $col := .Columns}}<th class="{{.Align}}"{{with $.Sort}}{{if eq .Key $col.Key}} aria-sort="{{if .Desc}}descending{{else}}ascending{{end}}"{{end}}{{end}}>{{.Label}}</th>{{end}}</tr></thead>
+<tbody>
+{{- $size := .PageSize}}
+{{- range $i, $row := .Rows}}
+<tr data-page="{{page $i $size}}">{{range $row}}<td class="{{.Align}}">{{if .Link}}<a href="{{.Link}}">{{.Text}}</a>{{else}}{{.Text}}{{end}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+{{if gt .Pages 1}}<div class="pager"><button type="button" data-step="-1">‹</button><span>{{.CurrentPage}} / {{.Pages}}</span><button type="button" data-step="1">›</button></div>{{end}}
+</section>{{end}}
+
+{{define "progressBar"}}<section class="widget progress">
+<h2>{{.Title}}</h2>
+<div class="track" role="progressbar" aria-valuenow="{{.Value}}" aria-valuemin="{{.MinValue}}" aria-valuemax="{{.MaxValue}}">
+{{- if .Segments}}{{range .Segments}}<div class="segment" title="{{.Name}}" style="left: {{printf "%.2f" .Start}}%; width: {{printf "%.2f" .Percent}}%; background: {{.Color}};"></div>{{end}}
+{{- else}}<div class="fill" style="width: {{printf "%.2f" .Percent}}%;{{if .Color}} background: {{.Color}};{{end}}"></div>{{end}}
+{{- if .HasTarget}}<div class="target" style="left: {{printf "%.2f" .Target}}%;"></div>{{end}}</div>
+{{if .ShowPercent}}<div class="percent">{{printf "%.0f" .Percent}}%{{with .Band}} · {{.}}{{end}}</div>{{end}}
+</section>{{end}}
+
+{{define "ranking"}}<section class="widget ranking">
+<h2>{{.Title}}</h2>
+<ol>
+{{- range .Items}}
+<li><span class="badge">{{.Position}}</span><span><strong>{{.Title}}</strong>{{if .Description}}<span class="item-description">{{.Description}}</span>{{end}}</span></li>
+{{- end}}
+</ol>
+</section>{{end}}
+
+{{define "filterBar"}}<section class="widget filter-bar">
+<h2>{{.Title}}</h2>
+<form class="filters" onsubmit="return false">
+{{- range .Filters}}{{template "filter" .}}{{end}}
+</form>
+</section>{{end}}
+
+{{define "filter"}}{{$f := .Render}}{{$type := index $f "type"}}<label class="filter">
+<span>{{index $f "label"}}</span>
+{{- if eq $type "date"}}<input type="date" name="{{index $f "key"}}">
+{{- else if eq $type "select"}}<select name="{{index $f "key"}}">{{range index $f "options"}}<option>{{.}}</option>{{end}}</select>
+{{- else if eq $type "multiSelect"}}<select name="{{index $f "key"}}" multiple>{{range index $f "options"}}<option>{{.}}</option>{{end}}</select>
+{{- else if eq $type "checkbox"}}<fieldset>{{$key := index $f "key"}}{{range index $f "options"}}<label><input type="checkbox" name="{{$key}}" value="{{.}}"> {{.}}</label>{{end}}</fieldset>
+{{- else if eq $type "radio"}}<fieldset>{{$key := index $f "key"}}{{range index $f "options"}}<label><input type="radio" name="{{$key}}" value="{{.}}"> {{.}}</label>{{end}}</fieldset>
+{{- else if or (eq $type "bool") (eq $type "toggle")}}<input type="checkbox" name="{{index $f "key"}}">
+{{- else if eq $type "number"}}<input type="number" name="{{index $f "key"}}" min="{{index $f "min"}}" max="{{index $f "max"}}">
+{{- else if eq $type "range"}}<span><input type="number" name="{{index $f "key"}}_from" min="{{index $f "min"}}" max="{{index $f "max"}}" placeholder="{{index $f "min"}}"> – <input type="number" name="{{index $f "key"}}_to" min="{{index $f "min"}}" max="{{index $f "max"}}" placeholder="{{index $f "max"}}"></span>
+{{- else if eq $type "slider"}}<input type="range" name="{{index $f "key"}}" min="{{index $f "min"}}" max="{{index $f "max"}}" value="{{index $f "value"}}">
+{{- else if eq $type "search"}}<input type="search" name="{{index $f "key"}}" placeholder="{{index $f "placeholder"}}">
+{{- else if eq $type "color"}}<input type="color" name="{{index $f "key"}}" value="{{index $f "value"}}">
+{{- else}}<input type="text" name="{{index $f "key"}}">{{end}}
+</label>{{end}}
+`
@@ -0,0 +1,240 @@
+package bussola
+
+import "fmt"
+
+// Breakpoint is a grid arrangement used from a minimum viewport width up
+type Breakpoint struct {
+	Name     string  `json:"name"`
+	MinWidth float64 `json:"minWidth"`
+	Columns  int     `json:"columns"`
+}
+
+// Placement is the position and span of a cell at a breakpoint. Auto marks
+// placements derived by the reflow rather than set as overrides.
+type Placement struct {
+	Row     int  `json:"row"`
+	Column  int  `json:"column"`
+	RowSpan int  `json:"rowSpan"`
+	ColSpan int  `json:"colSpan"`
+	Auto    bool `json:"auto,omitempty"`
+}
+
+// Arrangement is the placement of every cell of a grid at a breakpoint
+type Arrangement struct {
+	Breakpoint string // empty for the base layout
+	Columns    int
+	Rows       int
+	Cells      []ArrangedCell // in reading order of the base layout
+}
+
+// ArrangedCell is a cell of the grid with its placement in an arrangement
+type ArrangedCell struct {
+	Cell      *GridCell
+	Placement Placement
+}
+
+// AddBreakpoint declares a breakpoint used from minWidth up, e.g.
+// AddBreakpoint("sm", 576, 2)
+func (g *Grid) AddBreakpoint(name string, minWidth float64, columns int) {
+	g.Breakpoints = append(g.Breakpoints, Breakpoint{Name: name, MinWidth: minWidth, Columns: columns})
+}
+
+// Breakpoint returns the breakpoint with the given name
+func (g *Grid) Breakpoint(name string) (Breakpoint, bool) {
+	for _, bp := range g.Breakpoints {
+		if bp.Name == name {
+			return bp, true
+		}
+	}
+	return Breakpoint{}, false
+}
+
+// BreakpointFor returns the breakpoint with the largest MinWidth that fits
+// the viewport width
+func (g *Grid) BreakpointFor(width float64) (Breakpoint, bool) {
+	var best Breakpoint
+	found := false
+	for _, bp := range g.Breakpoints {
+		if bp.MinWidth <= width && (!found || bp.MinWidth > best.MinWidth) {
+			best, found = bp, true
+		}
+	}
+	return best, found
+}
+
+// SetOverride places the cell covering row and col at p when the named
+// breakpoint is active. It returns a *PlacementError when p does not fit
+// the columns of the breakpoint or the rows an arrangement can hold.
+func (g *Grid) SetOverride(row, col int, breakpoint string, p Placement) error {
+	cell, err := g.itemAt(row, col)
+	if err != nil {
+		return err
+	}
+	bp, ok := g.Breakpoint(breakpoint)
+	if !ok {
+		return fmt.Errorf("bussola: grid has no breakpoint %q", breakpoint)
+	}
+	if err := g.checkOverride(bp, p); err != nil {
+		return err
+	}
+
+	if cell.Overrides == nil {
+		cell.Overrides = map[string]Placement{}
+	}
+	p.Auto = false
+	cell.Overrides[breakpoint] = p
+	return nil
+}
+
+// checkOverride returns a *PlacementError when p cannot be used at bp
+func (g *Grid) checkOverride(bp Breakpoint, p Placement) error {
+	fail := func(err error) error {
+		return &PlacementError{Row: p.Row, Column: p.Column, RowSpan: p.RowSpan, ColSpan: p.ColSpan, Err: err}
+	}
+
+	switch {
+	case p.Row < 0 || p.Column < 0:
+		return fail(ErrOutOfRange)
+	case p.RowSpan <= 0 || p.ColSpan <= 0:
+		return fail(ErrInvalidSpan)
+	case p.Column+p.ColSpan > bp.Columns || p.RowSpan > g.arrangedRows() || p.Row > g.arrangedRows()-p.RowSpan:
+		return fail(ErrSpanOverflow)
+	}
+	return nil
+}
+
+// arrangedRows is the most rows an override may reach: as many as stacking
+// every coordinate of the grid in a single column takes
+func (g *Grid) arrangedRows() int {
+	return max(g.Rows, 1) * max(g.Columns, 1)
+}
+
+// ArrangeFor returns the arrangement of the breakpoint matching the viewport
+// width, or the base layout when no breakpoint matches
+func (g *Grid) ArrangeFor(width float64) *Arrangement {
+	if bp, ok := g.BreakpointFor(width); ok {
+		return g.arrange(bp)
+	}
+	return g.base()
+}
+
+// Arrange returns the arrangement of the named breakpoint, or the base
+// layout for the empty name. Cells keep their overrides; the others keep
+// their base placement when the breakpoint is as wide as the grid and are
+// otherwise stacked in reading order. Overrides that do not fit are
+// reflowed as well; Validate reports them.
+func (g *Grid) Arrange(breakpoint string) (*Arrangement, error) {
+	if breakpoint == "" {
+		return g.base(), nil
+	}
+	bp, ok := g.Breakpoint(breakpoint)
+	if !ok {
+		return nil, fmt.Errorf("bussola: grid has no breakpoint %q", breakpoint)
+	}
+	return g.arrange(bp), nil
+}
+
+func (g *Grid) base() *Arrangement {
+	a := &Arrangement{Columns: g.Columns, Rows: g.Rows}
+	for cell := range g.Items() {
+		a.Cells = append(a.Cells, ArrangedCell{Cell: cell, Placement: Placement{
+			Row: cell.Row, Column: cell.Column, RowSpan: cell.RowSpan, ColSpan: cell.ColSpan,
+		}})
+	}
+	return a
+}
+
+func (g *Grid) arrange(bp Breakpoint) *Arrangement {
+	a := &Arrangement{Breakpoint: bp.Name, Columns: max(bp.Columns, 1)}
+	claimed := map[cellKey]bool{}
+	fits := func(p Placement) bool {
+		if p.Row < 0 || p.Column < 0 || p.RowSpan <= 0 || p.ColSpan <= 0 || p.Column+p.ColSpan > a.Columns {
+			return false
+		}
+		for r := p.Row; r < p.Row+p.RowSpan; r++ {
+			for c := p.Column; c < p.Column+p.ColSpan; c++ {
+				if claimed[cellKey{r, c}] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	claim := func(i int, p Placement) {
+		a.Cells[i].Placement = p
+		for r := p.Row; r < p.Row+p.RowSpan; r++ {
+			for c := p.Column; c < p.Column+p.ColSpan; c++ {
+				claimed[cellKey{r, c}] = true
+			}
+		}
+		a.Rows = max(a.Rows, p.Row+p.RowSpan)
+	}
+
+	for cell := range g.Items() {
+		a.Cells = append(a.Cells, ArrangedCell{Cell: cell})
+	}
+
+	// Overrides come first, then base placements that still fit. Overrides
+	// that never went through SetOverride are checked again so a huge span
+	// cannot stall the walk below.
+	placed := make([]bool, len(a.Cells))
+	for i, ac := range a.Cells {
+		if p, ok := ac.Cell.Overrides[bp.Name]; ok && g.checkOverride(bp, p) == nil && fits(p) {
+			claim(i, p)
+			placed[i] = true
+		}
+	}
+	if bp.Columns >= g.Columns {
+		for i, ac := range a.Cells {
+			if _, ok := ac.Cell.Overrides[bp.Name]; ok || placed[i] {
+				continue
+			}
+			p := Placement{Row: ac.Cell.Row, Column: ac.Cell.Column, RowSpan: ac.Cell.RowSpan, ColSpan: ac.Cell.ColSpan, Auto: true}
+			if fits(p) {
+				claim(i, p)
+				placed[i] = true
+			}
+		}
+	}
+
+	// The rest is stacked in reading order, each cell after the previous one
+	row, col := 0, 0
+	for i, ac := range a.Cells {
+		if placed[i] {
+			continue
+		}
+		p := Placement{RowSpan: min(max(ac.Cell.RowSpan, 1), max(g.Rows, 1)), ColSpan: min(max(ac.Cell.ColSpan, 1), a.Columns), Auto: true}
+		for p.Row, p.Column = row, col; !fits(p); {
+			p.Column++
+			if p.Column+p.ColSpan > a.Columns {
+				p.Row, p.Column = p.Row+1, 0
+			}
+		}
+		claim(i, p)
+		row, col = p.Row, p.Column+p.ColSpan
+	}
+	return a
+}
+
+// arrangements returns the arrangement of every breakpoint, in order
+func (g *Grid) arrangements() []*Arrangement {
+	out := make([]*Arrangement, len(g.Breakpoints))
+	for i, bp := range g.Breakpoints {
+		out[i] = g.arrange(bp)
+	}
+	return out
+}
+
+// placementsOf returns the placements of every cell keyed by breakpoint name
+func placementsOf(arrangements []*Arrangement) map[*GridCell]map[string]Placement {
+	out := map[*GridCell]map[string]Placement{}
+	for _, a := range arrangements {
+		for _, ac := range a.Cells {
+			if out[ac.Cell] == nil {
+				out[ac.Cell] = map[string]Placement{}
+			}
+			out[ac.Cell][a.Breakpoint] = ac.Placement
+		}
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+package bussola
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetOverrideRejectsInvalidPlacements(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Placement
+		want error
+	}{
+		{"negative row", Placement{Row: -1, RowSpan: 1, ColSpan: 1}, ErrOutOfRange},
+		{"negative column", Placement{Column: -1, RowSpan: 1, ColSpan: 1}, ErrOutOfRange},
+		{"zero span", Placement{RowSpan: 0, ColSpan: 1}, ErrInvalidSpan},
+		{"too wide", Placement{Column: 1, RowSpan: 1, ColSpan: 2}, ErrSpanOverflow},
+		{"huge row span", Placement{RowSpan: 1 << 28, ColSpan: 1}, ErrSpanOverflow},
+		{"huge row", Placement{Row: 1<<63 - 1, RowSpan: 1, ColSpan: 1}, ErrSpanOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGrid("grid", 2, 2)
+			g.AddItem(NewIndicator("a"), 0, 0, 1, 1)
+			g.AddBreakpoint("sm", 576, 2)
+
+			var pe *PlacementError
+			if err := g.SetOverride(0, 0, "sm", tt.p); !errors.Is(err, tt.want) || !errors.As(err, &pe) {
+				t.Fatalf("SetOverride() error = %v, want %v", err, tt.want)
+			}
+			if len(g.Cells[0][0].Overrides) != 0 {
+				t.Errorf("Overrides = %v, want the rejected placement left out", g.Cells[0][0].Overrides)
+			}
+		})
+	}
+}
+
+func TestArrangeSkipsHugeOverrides(t *testing.T) {
+	g := NewGrid("grid", 2, 2)
+	g.AddItem(NewIndicator("a"), 0, 0, 1, 1)
+	g.AddItem(NewIndicator("b"), 0, 1, 1, 1)
+	g.AddBreakpoint("sm", 576, 1)
+	// Written directly, as a literal grid would, bypassing SetOverride
+	g.Cells[0][0].Overrides = map[string]Placement{"sm": {RowSpan: 1 << 28, ColSpan: 1}}
+
+	a, err := g.Arrange("sm")
+	if err != nil {
+		t.Fatalf("Arrange() error = %v", err)
+	}
+	want := []Placement{
+		{Row: 0, Column: 0, RowSpan: 1, ColSpan: 1, Auto: true},
+		{Row: 1, Column: 0, RowSpan: 1, ColSpan: 1, Auto: true},
+	}
+	for i, ac := range a.Cells {
+		if ac.Placement != want[i] {
+			t.Errorf("cell %d placement = %+v, want %+v", i, ac.Placement, want[i])
+		}
+	}
+	if a.Rows != 2 {
+		t.Errorf("Rows = %d, want 2", a.Rows)
+	}
+
+	var ve *ValidationError
+	if err := g.Validate(); !errors.As(err, &ve) || ve.Errors[0].Field != `cells[0][0].overrides["sm"]` {
+		t.Errorf("Validate() = %v, want an error on the override", err)
+	}
+}
+
+func TestGridUnmarshalRejectsHugeOverride(t *testing.T) {
+	var g Grid
+	err := g.UnmarshalJSON([]byte(`{"rows":1,"columns":1,"breakpoints":[{"name":"sm","minWidth":0,"columns":1}],` +
+		`"cells":[{"row":0,"column":0,"rowSpan":1,"colSpan":1,"content":{"type":"indicator"},` +
+		`"breakpoints":{"sm":{"row":0,"column":0,"rowSpan":268435456,"colSpan":1}}}]}`))
+	if !errors.Is(err, ErrSpanOverflow) {
+		t.Fatalf("UnmarshalJSON() error = %v, want ErrSpanOverflow", err)
+	}
+}
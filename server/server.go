@@ -0,0 +1,413 @@
+// Package server exposes registered dashboards over HTTP as JSON, HTML and
+// preview images.
+//
+// Routes:
+//
+//	GET /dashboards                          list of registered dashboards
+//	GET /dashboards/{id}                     Render JSON of a dashboard
+//	GET /dashboards/{id}/index.html          standalone HTML page
+//	GET /dashboards/{id}/preview.png         PNG preview
+//	GET /dashboards/{id}/preview.svg         SVG preview
+//	GET /dashboards/{id}/widgets/{path...}   Render JSON of a single widget
+//
+// A widget path lists the row and column of each cell from the layout down,
+// e.g. "1-0/0-2" is the cell at row 0, column 2 of the grid placed at row 1,
+// column 0 of the layout. Hidden widgets, or widgets inside a hidden grid,
+// are answered with 404 unless the query has "hidden=true".
+//
+// Table widgets are also offered as downloads by adding ".csv", ".tsv" or
+// ".jsonl" to their path. CSV downloads accept the query parameters
+// "delimiter" (a single character) and "header=false".
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/isaqueveras/bussola"
+	"github.com/isaqueveras/bussola/preview"
+	"github.com/isaqueveras/bussola/render/html"
+)
+
+// Registry holds the dashboards served by a Handler, keyed by id
+type Registry struct {
+	mu         sync.RWMutex
+	dashboards map[string]*bussola.Dashboard
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{dashboards: map[string]*bussola.Dashboard{}}
+}
+
+// Register adds or replaces the dashboard with the given id
+func (r *Registry) Register(id string, d *bussola.Dashboard) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dashboards[id] = d
+}
+
+// Remove deletes the dashboard with the given id
+func (r *Registry) Remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.dashboards, id)
+}
+
+// Get returns the dashboard with the given id
+func (r *Registry) Get(id string) (*bussola.Dashboard, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.dashboards[id]
+	return d, ok
+}
+
+// IDs returns the registered ids in sorted order
+func (r *Registry) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]string, 0, len(r.dashboards))
+	for id := range r.dashboards {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Handler serves the dashboards of a registry
+type Handler struct {
+	registry *Registry
+	mux      *http.ServeMux
+
+	// Preview configures the generated preview images. Its Format is
+	// ignored; the route decides it.
+	Preview preview.Options
+}
+
+// New creates a handler serving the dashboards of registry
+func New(registry *Registry) *Handler {
+	h := &Handler{registry: registry, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /dashboards", h.list)
+	h.mux.HandleFunc("GET /dashboards/{id}", h.dashboard)
+	h.mux.HandleFunc("GET /dashboards/{id}/index.html", h.page)
+	h.mux.HandleFunc("GET /dashboards/{id}/preview.png", h.preview(preview.FormatPNG, "image/png"))
+	h.mux.HandleFunc("GET /dashboards/{id}/preview.svg", h.preview(preview.FormatSVG, "image/svg+xml"))
+	h.mux.HandleFunc("GET /dashboards/{id}/widgets/{path...}", h.widget)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type summary struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	items := []summary{}
+	for _, id := range h.registry.IDs() {
+		if d, ok := h.registry.Get(id); ok {
+			items = append(items, summary{ID: id, Title: d.Title, Description: d.Description})
+		}
+	}
+	writeJSON(w, r, items)
+}
+
+func (h *Handler) dashboard(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	body, err := d.MarshalJSON()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeBody(w, r, "application/json", body)
+}
+
+func (h *Handler) page(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, d); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeBody(w, r, "text/html; charset=utf-8", buf.Bytes())
+}
+
+func (h *Handler) preview(format preview.Format, contentType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d, ok := h.lookup(w, r)
+		if !ok {
+			return
+		}
+
+		opts := h.Preview
+		opts.Format = format
+
+		var buf bytes.Buffer
+		if err := preview.Encode(d, &buf, &opts); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, preview.ErrNoLayout) {
+				status = http.StatusNotFound
+			}
+			writeError(w, status, err)
+			return
+		}
+		writeBody(w, r, contentType, buf.Bytes())
+	}
+}
+
+func (h *Handler) widget(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	widgetPath := r.PathValue("path")
+	format := bussola.ExportFormat(strings.TrimPrefix(path.Ext(widgetPath), "."))
+	widgetPath = strings.TrimSuffix(widgetPath, path.Ext(widgetPath))
+
+	component, visible, err := findWidget(d, widgetPath)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	show := false
+	if hidden := r.URL.Query().Get("hidden"); hidden != "" {
+		if show, err = strconv.ParseBool(hidden); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid hidden %q", hidden))
+			return
+		}
+	}
+	if !visible && !show {
+		writeError(w, http.StatusNotFound, fmt.Errorf("widget at %q is hidden", widgetPath))
+		return
+	}
+	if format == "" {
+		writeJSON(w, r, bussola.RenderComponent(component, nil))
+		return
+	}
+
+	table, ok := component.(*bussola.Table)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("widget at %q is not a table", widgetPath))
+		return
+	}
+	h.export(w, r, table, format)
+}
+
+// export streams a table download. Tables that cannot serve every row are
+// refused with 409 before anything is written. Errors after the first byte
+// can no longer be reported, so the connection is aborted to leave a
+// truncated response.
+func (h *Handler) export(w http.ResponseWriter, r *http.Request, t *bussola.Table, format bussola.ExportFormat) {
+	switch format {
+	case bussola.ExportCSV, bussola.ExportTSV, bussola.ExportJSONLines:
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown export format %q", format))
+		return
+	}
+
+	opts, err := exportOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !t.Complete() {
+		writeError(w, http.StatusConflict, bussola.ErrPartialTable)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+exportName(t.Title)+"."+string(format)+`"`)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	cw := &countingWriter{w: w}
+	if err := t.Export(r.Context(), cw, format, opts); err != nil {
+		if cw.n == 0 {
+			w.Header().Del("Content-Disposition")
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func exportOptions(r *http.Request) (*bussola.ExportOptions, error) {
+	opts := &bussola.ExportOptions{}
+	query := r.URL.Query()
+
+	if delimiter := query.Get("delimiter"); delimiter != "" {
+		c, size := utf8.DecodeRuneInString(delimiter)
+		if size != len(delimiter) || c == '"' || c == '\r' || c == '\n' || c == utf8.RuneError {
+			return nil, fmt.Errorf("invalid delimiter %q", delimiter)
+		}
+		opts.Comma = c
+	}
+	if header := query.Get("header"); header != "" {
+		show, err := strconv.ParseBool(header)
+		if err != nil {
+			return nil, fmt.Errorf("invalid header %q", header)
+		}
+		opts.NoHeader = !show
+	}
+	return opts, nil
+}
+
+// exportName turns a table title into a file name
+func exportName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		case r == ' ':
+			return '-'
+		}
+		return -1
+	}, title)
+	if name == "" {
+		return "table"
+	}
+	return name
+}
+
+type countingWriter struct {
+	w http.ResponseWriter
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+	return n, err
+}
+
+func (h *Handler) lookup(w http.ResponseWriter, r *http.Request) (*bussola.Dashboard, bool) {
+	id := r.PathValue("id")
+	d, ok := h.registry.Get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("dashboard %q not found", id))
+	}
+	return d, ok
+}
+
+// findWidget follows a path such as "1-0/0-2" from the dashboard layout and
+// reports whether the widget and every grid above it are visible
+func findWidget(d *bussola.Dashboard, path string) (bussola.Component, bool, error) {
+	var component bussola.Component = d.Layout
+	if d.Layout == nil {
+		return nil, false, fmt.Errorf("dashboard has no layout")
+	}
+	opts := &bussola.RenderOptions{}
+	visible := opts.Visible(d.Layout)
+
+	for _, step := range strings.Split(strings.Trim(path, "/"), "/") {
+		grid, ok := component.(*bussola.Grid)
+		if !ok {
+			return nil, false, fmt.Errorf("widget path %q goes past a non-grid component", path)
+		}
+
+		row, col, err := parseStep(step)
+		if err != nil {
+			return nil, false, fmt.Errorf("widget path %q: %w", path, err)
+		}
+		if row < 0 || row >= len(grid.Cells) || col < 0 || col >= len(grid.Cells[row]) || grid.Cells[row][col] == nil {
+			return nil, false, fmt.Errorf("no widget at %q", path)
+		}
+		component = grid.Cells[row][col].Content
+		if component != nil && !opts.Visible(component) {
+			visible = false
+		}
+	}
+
+	if component == nil {
+		return nil, false, fmt.Errorf("no widget at %q", path)
+	}
+	return component, visible, nil
+}
+
+func parseStep(step string) (int, int, error) {
+	r, c, ok := strings.Cut(step, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("step %q must be row-column", step)
+	}
+	row, err := strconv.Atoi(r)
+	if err != nil {
+		return 0, 0, fmt.Errorf("step %q must be row-column", step)
+	}
+	col, err := strconv.Atoi(c)
+	if err != nil {
+		return 0, 0, fmt.Errorf("step %q must be row-column", step)
+	}
+	return row, col, nil
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeBody(w, r, "application/json", body)
+}
+
+// writeBody sends body with an ETag computed from its content, answering
+// 304 Not Modified when the client already has it
+func writeBody(w http.ResponseWriter, r *http.Request, contentType string, body []byte) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if match := r.Header.Get("If-None-Match"); match != "" && etagMatches(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,325 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/isaqueveras/bussola"
+)
+
+func testDashboard() *bussola.Dashboard {
+	sales := bussola.NewIndicator("Sales")
+	sales.Value = 42
+
+	users := bussola.NewTable("Recent Users", []string{"ID", "Name"})
+	users.Data = []map[string]any{
+		{"id": 1, "name": "John"},
+		{"id": 2, "name": "Jane"},
+	}
+
+	chart := bussola.NewChart("Revenue", bussola.ChartLine)
+	chart.AddSeries("Revenue", "#1976D2", 1, 2, 3)
+	nested := bussola.NewGrid("Nested", 1, 1)
+	nested.AddItem(chart, 0, 0, 1, 1)
+
+	grid := bussola.NewGrid("Main", 2, 2)
+	grid.AddItem(sales, 0, 0, 1, 1)
+	grid.AddItem(users, 0, 1, 1, 1)
+	grid.AddItem(nested, 1, 0, 1, 2)
+
+	d := bussola.NewDashboard("Sales", "Sales overview")
+	d.SetLayout(grid)
+	return d
+}
+
+// decodedDashboard returns a dashboard loaded from JSON whose table at 0-0
+// holds only the first page of its 25 rows
+func decodedDashboard(t *testing.T) *bussola.Dashboard {
+	t.Helper()
+	table := bussola.NewTable("Paged", []string{"ID"})
+	table.Source = bussola.TableSourceFunc(func(ctx context.Context, req bussola.PageRequest) ([]map[string]any, int, error) {
+		rows := []map[string]any{}
+		for i := req.Offset; i < min(req.Offset+req.Limit, 25); i++ {
+			rows = append(rows, map[string]any{"id": i + 1})
+		}
+		return rows, 25, nil
+	})
+	if err := table.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	grid := bussola.NewGrid("Main", 1, 1)
+	grid.AddItem(table, 0, 0, 1, 1)
+	d := bussola.NewDashboard("Decoded", "")
+	d.SetLayout(grid)
+
+	decoded, err := bussola.LoadDashboard([]byte(d.GenerateJSON()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *bussola.Dashboard) {
+	t.Helper()
+	d := testDashboard()
+	registry := NewRegistry()
+	registry.Register("sales", d)
+	registry.Register("decoded", decodedDashboard(t))
+
+	srv := httptest.NewServer(New(registry))
+	t.Cleanup(srv.Close)
+	return srv, d
+}
+
+func get(t *testing.T, url string, header http.Header) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("GET %s: reading body: %v", url, err)
+	}
+	return resp, string(body)
+}
+
+func marshal(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRoutes(t *testing.T) {
+	srv, d := newTestServer(t)
+	grid := d.Layout
+
+	tests := []struct {
+		path        string
+		contentType string
+		body        func(body string) bool
+	}{
+		{"/dashboards", "application/json", func(body string) bool {
+			return body == `[{"id":"decoded","title":"Decoded","description":""},{"id":"sales","title":"Sales","description":"Sales overview"}]`
+		}},
+		{"/dashboards/sales", "application/json", func(body string) bool {
+			return body == d.GenerateJSON()
+		}},
+		{"/dashboards/sales/index.html", "text/html; charset=utf-8", func(body string) bool {
+			return strings.Contains(body, "<html") && strings.Contains(body, "Sales overview")
+		}},
+		{"/dashboards/sales/preview.png", "image/png", func(body string) bool {
+			return strings.HasPrefix(body, "\x89PNG\r\n\x1a\n")
+		}},
+		{"/dashboards/sales/preview.svg", "image/svg+xml", func(body string) bool {
+			return strings.HasPrefix(body, "<svg")
+		}},
+		{"/dashboards/sales/widgets/0-0", "application/json", func(body string) bool {
+			return body == marshal(t, grid.Cells[0][0].Content.Render())
+		}},
+		{"/dashboards/sales/widgets/1-0/0-0", "application/json", func(body string) bool {
+			nested := grid.Cells[1][0].Content.(*bussola.Grid)
+			return body == marshal(t, nested.Cells[0][0].Content.Render())
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, body := get(t, srv.URL+tt.path, nil)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", resp.StatusCode, body)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if resp.Header.Get("ETag") == "" {
+				t.Error("missing ETag")
+			}
+			if !tt.body(body) {
+				t.Errorf("unexpected body: %.200s", body)
+			}
+		})
+	}
+}
+
+func TestWidgetMatchesDashboard(t *testing.T) {
+	d := testDashboard()
+	if _, err := d.ComputeLayout(bussola.Size{Width: 800}); err != nil {
+		t.Fatal(err)
+	}
+	sales := d.Layout.Cells[0][0].Content.(*bussola.Indicator)
+	sales.ShowWhen("region", "north")
+	registry := NewRegistry()
+	registry.Register("sales", d)
+	srv := httptest.NewServer(New(registry))
+	defer srv.Close()
+
+	_, body := get(t, srv.URL+"/dashboards/sales", nil)
+	var full struct {
+		Layout struct {
+			Cells []struct {
+				Content json.RawMessage `json:"content"`
+			} `json:"cells"`
+		} `json:"layout"`
+	}
+	if err := json.Unmarshal([]byte(body), &full); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, widget := get(t, srv.URL+"/dashboards/sales/widgets/0-0", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", resp.StatusCode, widget)
+	}
+	if want := string(full.Layout.Cells[0].Content); widget != want {
+		t.Errorf("widget = %s, want the cell of the dashboard %s", widget, want)
+	}
+	if !strings.Contains(widget, `"frame":`) || !strings.Contains(widget, `"showWhen":`) {
+		t.Errorf("widget = %s, want its frame and rules", widget)
+	}
+}
+
+func TestHiddenWidgets(t *testing.T) {
+	srv, d := newTestServer(t)
+	d.Layout.Cells[0][0].Content.(*bussola.Indicator).Hide()
+	d.Layout.Cells[1][0].Content.(*bussola.Grid).Hide()
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/dashboards/sales/widgets/0-0", http.StatusNotFound},
+		{"/dashboards/sales/widgets/1-0", http.StatusNotFound},
+		{"/dashboards/sales/widgets/1-0/0-0", http.StatusNotFound},
+		{"/dashboards/sales/widgets/0-0?hidden=false", http.StatusNotFound},
+		{"/dashboards/sales/widgets/0-0?hidden=maybe", http.StatusBadRequest},
+		{"/dashboards/sales/widgets/0-0?hidden=true", http.StatusOK},
+		{"/dashboards/sales/widgets/1-0/0-0?hidden=true", http.StatusOK},
+		{"/dashboards/sales/widgets/0-1", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, body := get(t, srv.URL+tt.path, nil)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode, tt.status, body)
+			}
+		})
+	}
+
+	_, body := get(t, srv.URL+"/dashboards/sales/widgets/0-0?hidden=true", nil)
+	if !strings.Contains(body, `"visible":false`) {
+		t.Errorf("hidden widget = %s, want it marked hidden", body)
+	}
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	srv, _ := newTestServer(t)
+	url := srv.URL + "/dashboards/sales"
+
+	resp, _ := get(t, url, nil)
+	etag := resp.Header.Get("ETag")
+
+	resp, body := get(t, url, http.Header{"If-None-Match": {etag}})
+	if resp.StatusCode != http.StatusNotModified || body != "" {
+		t.Errorf("matching ETag: status = %d, body = %q, want 304 and no body", resp.StatusCode, body)
+	}
+
+	resp, _ = get(t, url, http.Header{"If-None-Match": {`"other", W/` + etag}})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("ETag in a list: status = %d, want 304", resp.StatusCode)
+	}
+
+	resp, _ = get(t, url, http.Header{"If-None-Match": {`"other"`}})
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("other ETag: status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/dashboards/missing", http.StatusNotFound},
+		{"/dashboards/missing/index.html", http.StatusNotFound},
+		{"/dashboards/missing/preview.png", http.StatusNotFound},
+		{"/dashboards/missing/widgets/0-0", http.StatusNotFound},
+		{"/dashboards/sales/widgets/5-5", http.StatusNotFound},
+		{"/dashboards/sales/widgets/0-0/0-0", http.StatusNotFound},
+		{"/dashboards/sales/widgets/first", http.StatusNotFound},
+		{"/dashboards/sales/widgets/0-0.csv", http.StatusNotFound},
+		{"/dashboards/sales/widgets/0-1.xlsx", http.StatusNotFound},
+		{"/dashboards/sales/widgets/0-1.csv?delimiter=ab", http.StatusBadRequest},
+		{"/dashboards/sales/widgets/0-1.csv?header=maybe", http.StatusBadRequest},
+		{"/dashboards/decoded/widgets/0-0.csv", http.StatusConflict},
+		{"/dashboards/decoded/widgets/0-0.jsonl", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, body := get(t, srv.URL+tt.path, nil)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode, tt.status, body)
+			}
+			if resp.Header.Get("Content-Disposition") != "" {
+				t.Error("error response offers a download")
+			}
+
+			var payload struct{ Error string }
+			if err := json.Unmarshal([]byte(body), &payload); err != nil || payload.Error == "" {
+				t.Errorf("body = %q, want a JSON error", body)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	tests := []struct {
+		path        string
+		contentType string
+		filename    string
+		body        string
+	}{
+		{"/dashboards/sales/widgets/0-1.csv", "text/csv; charset=utf-8", "recent-users.csv", "ID,Name\n1,John\n2,Jane\n"},
+		{"/dashboards/sales/widgets/0-1.csv?delimiter=%3B&header=false", "text/csv; charset=utf-8", "recent-users.csv", "1;John\n2;Jane\n"},
+		{"/dashboards/sales/widgets/0-1.tsv", "text/tab-separated-values; charset=utf-8", "recent-users.tsv", "ID\tName\n1\tJohn\n2\tJane\n"},
+		{"/dashboards/sales/widgets/0-1.jsonl", "application/jsonl", "recent-users.jsonl", "{\"id\":1,\"name\":\"John\"}\n{\"id\":2,\"name\":\"Jane\"}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, body := get(t, srv.URL+tt.path, nil)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", resp.StatusCode, body)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got, want := resp.Header.Get("Content-Disposition"), `attachment; filename="`+tt.filename+`"`; got != want {
+				t.Errorf("Content-Disposition = %q, want %q", got, want)
+			}
+			if body != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,393 @@
+package bussola
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds how long a single widget may wait for its source
+const DefaultTimeout = 10 * time.Second
+
+// Query describes the data requested by a widget
+type Query struct {
+	Target string    // query or URL bound to the widget
+	Widget Component // widget being resolved
+	Path   string    // position of the widget in the layout
+
+	// Filters holds the filter values the widget depends on
+	Filters FilterValues
+}
+
+// DataSource loads the data shown by widgets
+type DataSource interface {
+	Fetch(ctx context.Context, q Query) (any, error)
+}
+
+// SourceFunc adapts an ordinary function to the DataSource interface
+type SourceFunc func(ctx context.Context, q Query) (any, error)
+
+func (f SourceFunc) Fetch(ctx context.Context, q Query) (any, error) {
+	return f(ctx, q)
+}
+
+// Binding ties a widget to a data source registered in a Resolver
+type Binding struct {
+	Source  string        // name of the source, empty for the default source
+	Target  string        // query passed to the source
+	Timeout time.Duration // overrides the resolver timeout when set
+
+	// Filters lists the filter keys passed to the source. When nil the
+	// source receives every filter value.
+	Filters []string
+}
+
+// ResolveError reports a widget whose data could not be loaded
+type ResolveError struct {
+	Path   string
+	Widget Component
+	Err    error
+}
+
+func (e *ResolveError) Error() string { return e.Path + ": " + e.Err.Error() }
+
+func (e *ResolveError) Unwrap() error { return e.Err }
+
+// Resolver fills widget values from registered data sources before Render.
+// Each bound widget is fetched concurrently with its own timeout.
+type Resolver struct {
+	Timeout time.Duration // per widget, defaults to DefaultTimeout
+
+	mu      sync.RWMutex
+	sources map[string]DataSource
+}
+
+// NewResolver creates a resolver with no sources
+func NewResolver() *Resolver {
+	return &Resolver{sources: map[string]DataSource{}}
+}
+
+// Register adds a data source under name. The empty name registers the
+// default source, used by bindings without a Source.
+func (r *Resolver) Register(name string, src DataSource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sources == nil {
+		r.sources = map[string]DataSource{}
+	}
+	r.sources[name] = src
+}
+
+// resolvable is implemented by every widget that can receive data
+type resolvable interface {
+	Component
+	base() *BaseWidget
+	apply(v any) error
+}
+
+func (w *BaseWidget) base() *BaseWidget { return w }
+
+// Resolve fetches data for every bound widget of the dashboard and stores it
+// in the widget. Widgets that fail keep their previous value and report the
+// problem through Err and the "error" key of Render. The returned error joins
+// one *ResolveError per failed widget.
+func (r *Resolver) Resolve(ctx context.Context, d *Dashboard) error {
+	return r.ResolveWith(ctx, d, nil)
+}
+
+// ResolveWith is like Resolve but passes the selected filter values to the
+// sources, so a change of filter re-scopes the data of the bound widgets.
+// Tables with a Source also load their current page.
+func (r *Resolver) ResolveWith(ctx context.Context, d *Dashboard, values FilterValues) error {
+	if d.Layout == nil {
+		return nil
+	}
+
+	type job struct {
+		path    string
+		widget  resolvable
+		binding *Binding
+	}
+
+	jobs := []job{}
+	seen := map[resolvable]bool{}
+	d.Layout.walk("layout", func(path string, cell *GridCell) {
+		w, ok := cell.Content.(resolvable)
+		if !ok || seen[w] {
+			return
+		}
+		b, bound := bindingOf(w)
+		if !bound && !hasSource(w) {
+			return
+		}
+		j := job{path: path + ".content", widget: w}
+		if bound {
+			j.binding = &b
+		}
+		seen[w] = true
+		jobs = append(jobs, j)
+	})
+
+	errs := make([]error, len(jobs))
+	var wg sync.WaitGroup
+	for i, j := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var (
+				err     error
+				timeout time.Duration
+			)
+			if j.binding != nil {
+				err = r.resolve(ctx, j.path, j.widget, *j.binding, values)
+				timeout = j.binding.Timeout
+			}
+			if t, ok := j.widget.(*Table); ok && t.Source != nil && err == nil {
+				err = r.loadPage(ctx, t, timeout)
+			}
+			j.widget.base().err = err
+			if err != nil {
+				errs[i] = &ResolveError{Path: j.path, Widget: j.widget, Err: err}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (r *Resolver) resolve(ctx context.Context, path string, w resolvable, b Binding, values FilterValues) error {
+	r.mu.RLock()
+	src, ok := r.sources[b.Source]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("bussola: no data source named %q", b.Source)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout(b.Timeout))
+	defer cancel()
+
+	filters := values
+	if b.Filters != nil {
+		filters = values.Only(b.Filters...)
+	}
+
+	type result struct {
+		value any
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		v, err := src.Fetch(ctx, Query{Target: b.Target, Widget: w, Path: path, Filters: filters})
+		done <- result{v, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-done:
+		if res.err != nil {
+			return res.err
+		}
+		return w.apply(res.value)
+	}
+}
+
+// loadPage loads the current page of a table from its Source, bounded by
+// the timeout of the table's binding when it has one
+func (r *Resolver) loadPage(ctx context.Context, t *Table, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout(timeout))
+	defer cancel()
+
+	type result struct {
+		rows  []map[string]any
+		total int
+		err   error
+	}
+	done := make(chan result, 1)
+	src, req := t.Source, t.pageRequest()
+	go func() {
+		rows, total, err := src.LoadPage(ctx, req)
+		done <- result{rows, total, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-done:
+		if res.err != nil {
+			return res.err
+		}
+		t.page, t.total = res.rows, res.total
+		return nil
+	}
+}
+
+// timeout returns the timeout of a fetch, preferring the binding's own
+func (r *Resolver) timeout(binding time.Duration) time.Duration {
+	switch {
+	case binding > 0:
+		return binding
+	case r.Timeout > 0:
+		return r.Timeout
+	}
+	return DefaultTimeout
+}
+
+func hasSource(w resolvable) bool {
+	t, ok := w.(*Table)
+	return ok && t.Source != nil
+}
+
+// bindingOf returns the explicit binding of a widget or, for indicators,
+// one derived from a string Target
+func bindingOf(w resolvable) (Binding, bool) {
+	if b := w.base().binding; b != nil {
+		return *b, true
+	}
+	if ind, ok := w.(*Indicator); ok {
+		if target, ok := ind.Target.(string); ok && target != "" {
+			return Binding{Target: target}, true
+		}
+	}
+	return Binding{}, false
+}
+
+func (i *Indicator) apply(v any) error {
+	i.Value = v
+	return nil
+}
+
+// apply replaces the chart content. Values other than []Series go to Data,
+// which Normalized only reads when Series is empty, so Series is cleared
+// for a list of numbers and anything else is refused while Series is set.
+func (c *Chart) apply(v any) error {
+	if series, ok := v.([]Series); ok {
+		c.Series = series
+		c.Data = nil
+		return nil
+	}
+	if _, ok := toFloats(v); ok {
+		c.Series = nil
+	} else if len(c.Series) > 0 {
+		return fmt.Errorf("bussola: chart data %T cannot replace its series", v)
+	}
+	c.Data = v
+	return nil
+}
+
+func (t *Table) apply(v any) error {
+	switch rows := v.(type) {
+	case []map[string]any:
+		t.Data = rows
+	case []any:
+		data := make([]map[string]any, len(rows))
+		for i, row := range rows {
+			m, ok := row.(map[string]any)
+			if !ok {
+				return fmt.Errorf("bussola: table row %d is %T, not an object", i, row)
+			}
+			data[i] = m
+		}
+		t.Data = data
+	default:
+		return fmt.Errorf("bussola: unsupported table data %T", v)
+	}
+	return nil
+}
+
+func (p *ProgressBar) apply(v any) error {
+	n, ok := toFloat(v)
+	if !ok {
+		return fmt.Errorf("bussola: unsupported progress value %T", v)
+	}
+	p.Value = n
+	return nil
+}
+
+func (r *Ranking) apply(v any) error {
+	var rows []map[string]any
+	switch items := v.(type) {
+	case []RankingItem:
+		r.Items = items
+		return nil
+	case []map[string]any:
+		rows = items
+	case []any:
+		rows = make([]map[string]any, len(items))
+		for i, item := range items {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return fmt.Errorf("bussola: ranking item %d is %T, not an object", i, item)
+			}
+			rows[i] = m
+		}
+	default:
+		return fmt.Errorf("bussola: unsupported ranking items %T", v)
+	}
+
+	items := make([]RankingItem, len(rows))
+	for i, row := range rows {
+		item, err := rankingItemOf(row)
+		if err != nil {
+			return fmt.Errorf("bussola: ranking item %d: %w", i, err)
+		}
+		items[i] = item
+	}
+	r.Items = items
+	return nil
+}
+
+// rankingItemOf reads a ranking item from its JSON form
+func rankingItemOf(row map[string]any) (RankingItem, error) {
+	var item RankingItem
+	if v, ok := row["position"]; ok {
+		n, ok := toFloat(v)
+		if !ok || n != math.Trunc(n) {
+			return item, fmt.Errorf("position %v is not a whole number", v)
+		}
+		item.Position = int(n)
+	}
+
+	fields := []struct {
+		key string
+		dst *string
+	}{
+		{"title", &item.Title},
+		{"description", &item.Description},
+		{"imageUrl", &item.ImageURL},
+	}
+	for _, f := range fields {
+		switch v := row[f.key].(type) {
+		case nil:
+		case string:
+			*f.dst = v
+		default:
+			return item, fmt.Errorf("%s is %T, not a string", f.key, v)
+		}
+	}
+	return item, nil
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
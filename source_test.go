@@ -0,0 +1,289 @@
+package bussola
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResolveRankingFromJSON(t *testing.T) {
+	var data any
+	body := `[{"position":1,"title":"Alpha","description":"Top","imageUrl":"a.png"},{"position":2,"title":"Beta"}]`
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	ranking := NewRanking("ranking")
+	ranking.Bind(Binding{Target: "ranking"})
+	grid := NewGrid("grid", 1, 1)
+	grid.AddItem(ranking, 0, 0, 1, 1)
+	d := NewDashboard("dashboard", "")
+	d.SetLayout(grid)
+
+	r := NewResolver()
+	r.Register("", SourceFunc(func(ctx context.Context, q Query) (any, error) {
+		return data, nil
+	}))
+	if err := r.Resolve(context.Background(), d); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	want := []RankingItem{
+		{Position: 1, Title: "Alpha", Description: "Top", ImageURL: "a.png"},
+		{Position: 2, Title: "Beta"},
+	}
+	if !reflect.DeepEqual(ranking.Items, want) {
+		t.Errorf("Items = %+v, want %+v", ranking.Items, want)
+	}
+}
+
+func TestRankingApplyRejectsBadItems(t *testing.T) {
+	tests := []any{
+		[]any{"not an object"},
+		[]any{map[string]any{"position": 1.5}},
+		[]map[string]any{{"title": 3.0}},
+		"items",
+	}
+	for _, v := range tests {
+		r := NewRanking("ranking")
+		if err := r.apply(v); err == nil {
+			t.Errorf("apply(%#v) succeeded, want an error", v)
+		}
+	}
+}
+
+func TestChartApplyReplacesSeries(t *testing.T) {
+	chart := NewChart("revenue", ChartLine)
+	chart.AddSeries("old", "#000", 1, 2, 3)
+
+	if err := chart.apply([]any{4.0, 5.0}); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	got := chart.Normalized().Series
+	want := []Series{{Name: "revenue", Data: []float64{4, 5}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Series = %+v, want the fetched data %+v", got, want)
+	}
+
+	chart.AddSeries("old", "#000", 1, 2, 3)
+	if err := chart.apply(map[string]any{"points": 3.0}); err == nil {
+		t.Error("apply() of data that cannot replace the series succeeded")
+	}
+	if len(chart.Series) != 1 || chart.Series[0].Name != "old" {
+		t.Errorf("Series = %+v after a refused apply, want it untouched", chart.Series)
+	}
+
+	series := []Series{{Name: "new", Data: []float64{7}}}
+	if err := chart.apply(series); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if !reflect.DeepEqual(chart.Normalized().Series, series) || chart.Data != nil {
+		t.Errorf("chart = %+v, want only the applied series", chart)
+	}
+}
+
+// fakeSource answers each Target with a fixed value or error, after an
+// optional delay, and records the queries it receives
+type fakeSource struct {
+	delay  time.Duration
+	values map[string]any
+	errs   map[string]error
+
+	mu      sync.Mutex
+	queries []Query
+	active  int
+	peak    int
+}
+
+func (f *fakeSource) Fetch(ctx context.Context, q Query) (any, error) {
+	f.mu.Lock()
+	f.queries = append(f.queries, q)
+	f.active++
+	f.peak = max(f.peak, f.active)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.active--
+		f.mu.Unlock()
+	}()
+
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if err := f.errs[q.Target]; err != nil {
+		return nil, err
+	}
+	return f.values[q.Target], nil
+}
+
+// boundDashboard places one indicator per binding in a single row
+func boundDashboard(bindings ...Binding) (*Dashboard, []*Indicator) {
+	grid := NewGrid("grid", 1, len(bindings))
+	indicators := make([]*Indicator, len(bindings))
+	for i, b := range bindings {
+		indicators[i] = NewIndicator(b.Target)
+		indicators[i].Bind(b)
+		grid.AddItem(indicators[i], 0, i, 1, 1)
+	}
+	d := NewDashboard("dashboard", "")
+	d.SetLayout(grid)
+	return d, indicators
+}
+
+func TestResolveFetchesConcurrently(t *testing.T) {
+	src := &fakeSource{delay: 50 * time.Millisecond, values: map[string]any{"a": 1, "b": 2, "c": 3}}
+	r := NewResolver()
+	r.Register("", src)
+	d, indicators := boundDashboard(Binding{Target: "a"}, Binding{Target: "b"}, Binding{Target: "c"})
+
+	if err := r.Resolve(context.Background(), d); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if src.peak != 3 {
+		t.Errorf("at most %d fetches ran at once, want 3", src.peak)
+	}
+	for i, ind := range indicators {
+		if ind.Value != i+1 {
+			t.Errorf("indicator %d Value = %v, want %d", i, ind.Value, i+1)
+		}
+	}
+}
+
+func TestResolveTimeouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver time.Duration
+		binding  time.Duration
+		timedOut bool
+	}{
+		{"resolver timeout", 10 * time.Millisecond, 0, true},
+		{"binding timeout", time.Minute, 10 * time.Millisecond, true},
+		{"binding overrides resolver", 10 * time.Millisecond, time.Minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewResolver()
+			r.Timeout = tt.resolver
+			r.Register("", &fakeSource{delay: 100 * time.Millisecond, values: map[string]any{"a": 1}})
+			d, _ := boundDashboard(Binding{Target: "a", Timeout: tt.binding})
+
+			err := r.Resolve(context.Background(), d)
+			if timedOut := errors.Is(err, context.DeadlineExceeded); timedOut != tt.timedOut {
+				t.Errorf("Resolve() error = %v, want timed out %v", err, tt.timedOut)
+			}
+		})
+	}
+}
+
+func TestResolveTablePageUsesBindingTimeout(t *testing.T) {
+	table := NewTable("table", []string{"ID"})
+	table.Bind(Binding{Target: "rows", Timeout: 10 * time.Millisecond})
+	table.Source = TableSourceFunc(func(ctx context.Context, req PageRequest) ([]map[string]any, int, error) {
+		<-ctx.Done()
+		return nil, 0, ctx.Err()
+	})
+	grid := NewGrid("grid", 1, 1)
+	grid.AddItem(table, 0, 0, 1, 1)
+	d := NewDashboard("dashboard", "")
+	d.SetLayout(grid)
+
+	r := NewResolver()
+	r.Timeout = time.Minute
+	r.Register("", SourceFunc(func(ctx context.Context, q Query) (any, error) {
+		return []any{}, nil
+	}))
+
+	done := make(chan error, 1)
+	go func() { done <- r.Resolve(context.Background(), d) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Resolve() error = %v, want the page load to time out", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("loading the page ignored the binding timeout")
+	}
+}
+
+func TestResolveErrorIsClearedOnSuccess(t *testing.T) {
+	fail := errors.New("source down")
+	src := &fakeSource{values: map[string]any{"a": 42}, errs: map[string]error{"a": fail}}
+	r := NewResolver()
+	r.Register("", src)
+	d, indicators := boundDashboard(Binding{Target: "a"})
+	ind := indicators[0]
+	ind.Value = 7
+
+	err := r.Resolve(context.Background(), d)
+	var re *ResolveError
+	if !errors.As(err, &re) || !errors.Is(err, fail) || re.Path != "layout.cells[0][0].content" {
+		t.Fatalf("Resolve() error = %v, want a *ResolveError wrapping %v", err, fail)
+	}
+	if ind.Err() != fail || ind.Render()["error"] != "source down" {
+		t.Errorf("Err() = %v, error key = %v, want the fetch error", ind.Err(), ind.Render()["error"])
+	}
+	if ind.Value != 7 {
+		t.Errorf("Value = %v after a failed fetch, want the previous value", ind.Value)
+	}
+
+	src.errs = nil
+	if err := r.Resolve(context.Background(), d); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if _, ok := ind.Render()["error"]; ok || ind.Err() != nil {
+		t.Errorf("Err() = %v after a later success, want nil", ind.Err())
+	}
+	if ind.Value != 42 {
+		t.Errorf("Value = %v, want 42", ind.Value)
+	}
+}
+
+func TestResolveUnknownSource(t *testing.T) {
+	r := NewResolver()
+	d, indicators := boundDashboard(Binding{Source: "warehouse", Target: "a"})
+
+	err := r.Resolve(context.Background(), d)
+	if err == nil || !strings.Contains(err.Error(), `no data source named "warehouse"`) {
+		t.Fatalf("Resolve() error = %v, want the unknown source", err)
+	}
+	if indicators[0].Err() == nil {
+		t.Error("Err() = nil for a widget bound to an unknown source")
+	}
+}
+
+func TestResolveNarrowsFilters(t *testing.T) {
+	src := &fakeSource{}
+	r := NewResolver()
+	r.Register("", src)
+	d, _ := boundDashboard(
+		Binding{Target: "all"},
+		Binding{Target: "region", Filters: []string{"region", "missing"}},
+		Binding{Target: "none", Filters: []string{}},
+	)
+
+	values := FilterValues{"region": "north", "status": "open"}
+	if err := r.ResolveWith(context.Background(), d, values); err != nil {
+		t.Fatalf("ResolveWith() error = %v", err)
+	}
+
+	want := map[string]FilterValues{
+		"all":    values,
+		"region": {"region": "north"},
+		"none":   {},
+	}
+	for _, q := range src.queries {
+		if len(q.Filters) != len(want[q.Target]) || !reflect.DeepEqual(map[string]any(q.Filters), map[string]any(want[q.Target])) {
+			t.Errorf("Filters for %s = %v, want %v", q.Target, q.Filters, want[q.Target])
+		}
+	}
+	if len(src.queries) != 3 {
+		t.Errorf("source received %d queries, want 3", len(src.queries))
+	}
+}
@@ -0,0 +1,592 @@
+package bussola
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// flushSize is the amount of buffered output that triggers a write
+const flushSize = 32 << 10
+
+// encoder writes the JSON of a dashboard straight to an io.Writer, without
+// building the map tree of Render. Its output is byte-identical to
+// json.Marshal(d.Render()): object keys are written in sorted order and
+// values follow the encoding/json rules.
+type encoder struct {
+	w   io.Writer
+	buf []byte
+	err error
+
+	path string // path of the component being written
+	key  string // key being written, used in error paths
+
+	opts *RenderOptions
+
+	// fallback encodes values without a fast path, such as structs
+	fallback bytes.Buffer
+	enc      *json.Encoder
+}
+
+// streamer is implemented by the components that write their own JSON
+type streamer interface {
+	encodeJSON(e *encoder)
+}
+
+func newEncoder(w io.Writer, opts *RenderOptions) *encoder {
+	e := &encoder{w: w, buf: make([]byte, 0, flushSize+1024), opts: opts}
+	e.enc = json.NewEncoder(&e.fallback)
+	return e
+}
+
+// encode writes the dashboard JSON to w
+func (d *Dashboard) encode(w io.Writer, opts *RenderOptions) error {
+	e := newEncoder(w, opts)
+	d.encodeJSON(e)
+	e.flush()
+	return e.err
+}
+
+func (e *encoder) flush() {
+	if len(e.buf) == 0 || e.err != nil {
+		return
+	}
+	if _, err := e.w.Write(e.buf); err != nil {
+		e.err = err
+	}
+	e.buf = e.buf[:0]
+}
+
+func (e *encoder) maybeFlush() {
+	if len(e.buf) >= flushSize {
+		e.flush()
+	}
+}
+
+func (e *encoder) fail(err error) {
+	if e.err == nil {
+		e.err = &JSONError{Path: joinPath(e.path, e.key), Err: err}
+	}
+}
+
+// object starts a JSON object; keys must be written in sorted order
+func (e *encoder) object() *object {
+	e.buf = append(e.buf, '{')
+	return &object{e: e}
+}
+
+type object struct {
+	e *encoder
+	n int
+}
+
+func (o *object) field(key string) *encoder {
+	if o.n > 0 {
+		o.e.buf = append(o.e.buf, ',')
+	}
+	o.n++
+	o.e.key = key
+	o.e.buf = appendString(o.e.buf, key)
+	o.e.buf = append(o.e.buf, ':')
+	return o.e
+}
+
+func (o *object) end() {
+	o.e.buf = append(o.e.buf, '}')
+}
+
+// enter sets the path of a nested component and returns a function that
+// restores the previous one
+func (e *encoder) enter(path string) func() {
+	prevPath, prevKey := e.path, e.key
+	e.path, e.key = path, ""
+	return func() { e.path, e.key = prevPath, prevKey }
+}
+
+func (e *encoder) null() {
+	e.buf = append(e.buf, "null"...)
+}
+
+func (e *encoder) string(s string) {
+	e.buf = appendString(e.buf, s)
+}
+
+func (e *encoder) bool(b bool) {
+	e.buf = strconv.AppendBool(e.buf, b)
+}
+
+func (e *encoder) int(n int) {
+	e.buf = strconv.AppendInt(e.buf, int64(n), 10)
+}
+
+// float writes f like encoding/json does
+func (e *encoder) float(f float64) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		e.fail(&json.UnsupportedValueError{Value: reflect.ValueOf(f), Str: strconv.FormatFloat(f, 'g', -1, 64)})
+		e.null()
+		return
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	e.buf = strconv.AppendFloat(e.buf, f, format, -1, 64)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(e.buf)
+		if n >= 4 && e.buf[n-4] == 'e' && e.buf[n-3] == '-' && e.buf[n-2] == '0' {
+			e.buf[n-2] = e.buf[n-1]
+			e.buf = e.buf[:n-1]
+		}
+	}
+}
+
+func (e *encoder) strings(s []string) {
+	if s == nil {
+		e.null()
+		return
+	}
+	e.buf = append(e.buf, '[')
+	for i, v := range s {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		e.string(v)
+	}
+	e.buf = append(e.buf, ']')
+}
+
+// any writes a free-form value, with fast paths for the types produced by
+// JSON decoding and data sources
+func (e *encoder) any(v any) {
+	switch v := v.(type) {
+	case nil:
+		e.null()
+	case string:
+		e.string(v)
+	case bool:
+		e.bool(v)
+	case float64:
+		e.float(v)
+	case int:
+		e.int(v)
+	case []string:
+		e.strings(v)
+	case []float64:
+		if v == nil {
+			e.null()
+			return
+		}
+		e.buf = append(e.buf, '[')
+		for i, f := range v {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			e.float(f)
+		}
+		e.buf = append(e.buf, ']')
+	case []any:
+		if v == nil {
+			e.null()
+			return
+		}
+		e.buf = append(e.buf, '[')
+		for i, item := range v {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			e.any(item)
+		}
+		e.buf = append(e.buf, ']')
+	case []map[string]any:
+		if v == nil {
+			e.null()
+			return
+		}
+		e.buf = append(e.buf, '[')
+		for i, item := range v {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			e.any(item)
+			e.maybeFlush()
+		}
+		e.buf = append(e.buf, ']')
+	case map[string]any:
+		e.mapping(v)
+	default:
+		e.marshal(v)
+	}
+}
+
+func (e *encoder) mapping(m map[string]any) {
+	if m == nil {
+		e.null()
+		return
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	e.buf = append(e.buf, '{')
+	for i, key := range keys {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		e.string(key)
+		e.buf = append(e.buf, ':')
+		e.any(m[key])
+	}
+	e.buf = append(e.buf, '}')
+}
+
+// marshal writes v through encoding/json
+func (e *encoder) marshal(v any) {
+	e.fallback.Reset()
+	if err := e.enc.Encode(v); err != nil {
+		e.fail(err)
+		e.null()
+		return
+	}
+	e.buf = append(e.buf, bytes.TrimSuffix(e.fallback.Bytes(), []byte("\n"))...)
+}
+
+// component writes c, using its own encoder when it has one
+func (e *encoder) component(c Component) {
+	switch c := c.(type) {
+	case nil:
+		e.null()
+	case streamer:
+		c.encodeJSON(e)
+	default:
+		e.mapping(decorateLayout(c.Render(), c, e.opts))
+	}
+}
+
+// error writes the "error" key added by decorate
+func (w *BaseWidget) encodeError(o *object) {
+	if w.err != nil {
+		o.field("error").string(w.err.Error())
+	}
+}
+
+// encodeFrame, encodeShowWhen and encodeVisible write the keys added by
+// decorateLayout
+func (w *BaseWidget) encodeFrame(o *object) {
+	if f := w.Frame(); f != (Rect{}) {
+		o.field("frame").marshal(f)
+	}
+}
+
+func (w *BaseWidget) encodeShowWhen(o *object) {
+	if len(w.rules) > 0 {
+		o.field("showWhen").marshal(w.rules)
+	}
+}
+
+func (e *encoder) encodeVisible(o *object, c Component) {
+	if !e.opts.Visible(c) {
+		o.field("visible").bool(false)
+	}
+}
+
+func (d *Dashboard) encodeJSON(e *encoder) {
+	o := e.object()
+	o.field("description").string(d.Description)
+	if d.Layout != nil {
+		o.field("layout")
+		restore := e.enter("layout")
+		d.Layout.encodeJSON(e)
+		restore()
+	}
+	o.field("theme").marshal(d.Theme)
+	o.field("title").string(d.Title)
+	o.end()
+}
+
+func (g *Grid) encodeJSON(e *encoder) {
+	base := e.path
+	o := e.object()
+	if g.AutoGrow {
+		o.field("autoGrow").bool(true)
+	}
+	arrangements := g.arrangements()
+	placements := placementsOf(arrangements)
+	if len(arrangements) > 0 {
+		o.field("breakpoints")
+		e.buf = append(e.buf, '[')
+		for i, bp := range g.Breakpoints {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			bo := e.object()
+			bo.field("columns").int(bp.Columns)
+			bo.field("minWidth").float(bp.MinWidth)
+			bo.field("name").string(bp.Name)
+			bo.field("rows").int(arrangements[i].Rows)
+			bo.end()
+		}
+		e.buf = append(e.buf, ']')
+	}
+	o.field("cells")
+	e.buf = append(e.buf, '[')
+	n := 0
+	for i := range g.Cells {
+		for j := range g.Cells[i] {
+			cell := g.Cells[i][j]
+			if cell == nil || e.opts.omit(cell.Content) {
+				continue
+			}
+			if n > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			n++
+
+			path := fmt.Sprintf("%s.cells[%d][%d]", base, i, j)
+			restore := e.enter(path)
+			c := e.object()
+			if len(arrangements) > 0 {
+				c.field("breakpoints").marshal(placements[cell])
+			}
+			c.field("colSpan").int(cell.ColSpan)
+			c.field("column").int(cell.Column)
+			c.field("content")
+			leave := e.enter(path + ".content")
+			e.component(cell.Content)
+			leave()
+			c.field("row").int(cell.Row)
+			c.field("rowSpan").int(cell.RowSpan)
+			c.end()
+			restore()
+			e.maybeFlush()
+		}
+	}
+	e.buf = append(e.buf, ']')
+	o.field("columns").int(g.Columns)
+	g.encodeFrame(o)
+	if g.MaxRows > 0 {
+		o.field("maxRows").int(g.MaxRows)
+	}
+	o.field("padding").float(g.Padding)
+	o.field("rows").int(g.Rows)
+	g.encodeShowWhen(o)
+	o.field("spacing").float(g.Spacing)
+	o.field("title").string(g.Title)
+	e.encodeVisible(o, g)
+	o.end()
+}
+
+func (c *Chart) encodeJSON(e *encoder) {
+	n := c.Normalized()
+	o := e.object()
+	o.field("chartType").string(string(c.Type))
+	o.field("data").any(c.Data)
+	c.encodeError(o)
+	c.encodeFrame(o)
+	o.field("options").any(c.Options)
+	o.field("series").marshal(n.Series)
+	c.encodeShowWhen(o)
+	o.field("stacked").bool(c.Stacked)
+	o.field("subtitle").string(c.Subtitle)
+	o.field("title").string(c.Title)
+	o.field("type").string("chart")
+	e.encodeVisible(o, c)
+	o.field("xAxis").marshal(n.XAxis)
+	o.field("yAxis").marshal(n.YAxis)
+	o.end()
+}
+
+func (t *Table) encodeJSON(e *encoder) {
+	o := e.object()
+	o.field("columns").marshal(t.ResolvedColumns())
+	o.field("currentPage").int(t.CurrentPage)
+	o.field("data").any(t.CurrentRows())
+	t.encodeError(o)
+	t.encodeFrame(o)
+	o.field("headers").strings(t.Headers)
+	o.field("pageSize").int(t.PageSize)
+	t.encodeShowWhen(o)
+	if t.Sort != nil {
+		o.field("sort").marshal(t.Sort)
+	}
+	o.field("title").string(t.Title)
+	o.field("totalPages").int(t.TotalPages())
+	o.field("totalRows").int(t.TotalRows())
+	o.field("type").string("table")
+	e.encodeVisible(o, t)
+	o.end()
+}
+
+func (i *Indicator) encodeJSON(e *encoder) {
+	o := e.object()
+	o.field("description").string(i.Description)
+	i.encodeError(o)
+	i.encodeFrame(o)
+	i.encodeShowWhen(o)
+	o.field("target").any(i.Target)
+	o.field("title").string(i.Title)
+	o.field("trend").float(i.Trend)
+	o.field("type").string("indicator")
+	o.field("unit").string(i.Unit)
+	o.field("value").any(i.Value)
+	e.encodeVisible(o, i)
+	o.end()
+}
+
+func (p *ProgressBar) encodeJSON(e *encoder) {
+	o := e.object()
+	if band := p.Band(); band != nil {
+		o.field("band").string(band.Name)
+		o.field("color").string(band.Color)
+	}
+	p.encodeError(o)
+	p.encodeFrame(o)
+	o.field("maxValue").float(p.MaxValue)
+	if p.MinValue != 0 {
+		o.field("minValue").float(p.MinValue)
+	}
+	o.field("percent").float(p.Percent())
+	if len(p.Segments) > 0 {
+		o.field("segments")
+		e.buf = append(e.buf, '[')
+		for i, s := range p.Segments {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			so := e.object()
+			so.field("color").string(s.Color)
+			so.field("name").string(s.Name)
+			so.field("percent").float(p.percentOf(s.Value))
+			so.field("value").float(s.Value)
+			so.end()
+		}
+		e.buf = append(e.buf, ']')
+	}
+	o.field("showPercent").bool(p.ShowPercent)
+	p.encodeShowWhen(o)
+	if p.Target != nil {
+		o.field("target").float(*p.Target)
+		o.field("targetPercent").float(p.percentOf(*p.Target - p.MinValue))
+	}
+	if len(p.Thresholds) > 0 {
+		o.field("thresholds").marshal(p.Thresholds)
+	}
+	o.field("title").string(p.Title)
+	o.field("type").string("progressBar")
+	o.field("value").float(p.Value)
+	e.encodeVisible(o, p)
+	o.end()
+}
+
+func (f *FilterBar) encodeJSON(e *encoder) {
+	o := e.object()
+	o.field("filters")
+	e.buf = append(e.buf, '[')
+	for i, flt := range f.Filters {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		e.mapping(flt.Render())
+	}
+	e.buf = append(e.buf, ']')
+	f.encodeFrame(o)
+	f.encodeShowWhen(o)
+	o.field("title").string(f.Title)
+	o.field("type").string("filterBar")
+	e.encodeVisible(o, f)
+	o.end()
+}
+
+func (r *Ranking) encodeJSON(e *encoder) {
+	o := e.object()
+	r.encodeError(o)
+	r.encodeFrame(o)
+	o.field("items")
+	e.buf = append(e.buf, '[')
+	for i, it := range r.Items {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		item := e.object()
+		item.field("description").string(it.Description)
+		if it.ImageURL != "" {
+			item.field("imageUrl").string(it.ImageURL)
+		}
+		item.field("position").int(it.Position)
+		item.field("title").string(it.Title)
+		item.end()
+	}
+	e.buf = append(e.buf, ']')
+	o.field("order").string(r.Order)
+	r.encodeShowWhen(o)
+	o.field("title").string(r.Title)
+	o.field("type").string("ranking")
+	e.encodeVisible(o, r)
+	o.end()
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendString appends s as a JSON string with the escaping of
+// encoding/json, including its HTML-safe escapes
+func appendString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '\\', '"':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
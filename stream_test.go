@@ -0,0 +1,233 @@
+package bussola
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+// fullDashboard returns a dashboard using every widget and filter type,
+// nested grids, breakpoints, hidden widgets and frames
+func fullDashboard(t testing.TB) *Dashboard {
+	t.Helper()
+
+	filters := NewFilterBar("Filters")
+	filters.AddFilter(NewFilterDate("Period", "period"))
+	region := NewFilterSelect("Region", "region", []string{"North", "South"})
+	region.Default = "North"
+	filters.AddFilter(region)
+	filters.AddFilter(NewFilterText("Client", "client"))
+	filters.AddFilter(NewFilterBool("Active", "active"))
+	filters.AddFilter(NewFilterNumber("Amount", "amount", 0, 100))
+	filters.AddFilter(NewFilterRange("Price", "price", 1, 50))
+	status := NewFilterCheckbox("Status", "status", []string{"open", "closed"})
+	status.Default = []string{"open"}
+	filters.AddFilter(status)
+	level := NewFilterRadio("Level", "level", []string{"low", "high"})
+	level.Default = "low"
+	filters.AddFilter(level)
+	tags := NewFilterMultiSelect("Tags", "tags", []string{"a", "b", "c"})
+	tags.Default = []string{"a", "c"}
+	filters.AddFilter(tags)
+	filters.AddFilter(NewFilterSlider("Score", "score", 0, 10, 2.5))
+	filters.AddFilter(NewFilterToggle("Compare", "compare"))
+	filters.AddFilter(NewFilterSearch("Search", "search", "Name or ID"))
+	filters.AddFilter(NewFilterColor("Color", "color", "#ff0000"))
+
+	sales := NewIndicator("Sales")
+	sales.Value = 1234.5
+	sales.Unit = "R$"
+	sales.Trend = -2.5
+	sales.Description = "Total sales"
+	sales.Target = "sales"
+	sales.ShowWhen("region", "North")
+
+	failing := NewIndicator("Failing")
+	failing.err = errors.New("source unavailable")
+
+	hidden := NewIndicator("Hidden")
+	hidden.Hide()
+
+	line := NewChart("Revenue", ChartLine)
+	line.SetCategories("Jan", "Feb", "Mar")
+	line.AddSeries("Revenue", "#1976D2", 10, 20, 15)
+	line.AddSeries("Costs", "", 5, 8, 9)
+	low := 0.0
+	line.YAxis = &YAxis{Unit: "R$", Min: &low}
+	line.Stacked = true
+
+	timeline := NewChart("Visits", ChartArea)
+	timeline.SetTimes(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+	timeline.AddSeries("Visits", "", 3, 4)
+
+	scatter := NewChart("Scatter", ChartScatter)
+	scatter.Series = []Series{{Name: "Points", Data: []float64{1, 2, 3}, X: []float64{0.5, 1.5, 2.5}}}
+
+	legacy := NewChart("Legacy", ChartBar)
+	legacy.Data = []any{1.0, 2.0}
+	legacy.Options = map[string]any{"color": "#fff", "labels": []any{"a", "b"}}
+
+	users := NewTable("Users", []string{"ID", "Name", "Last Access"})
+	users.AddColumn(NewColumn("id", "ID", ColumnNumber))
+	users.AddColumn(NewColumn("name", "Name", ColumnString))
+	lastAccess := NewColumn("lastAccess", "Last Access", ColumnDate)
+	lastAccess.Format = "02/01/2006"
+	users.AddColumn(lastAccess)
+	users.Data = []map[string]any{
+		{"id": 1.0, "name": "John", "lastAccess": "2025-06-26"},
+		{"id": 2.0, "name": "Jane", "lastAccess": "2025-06-25"},
+	}
+	users.SortBy("lastAccess", true)
+
+	rows := make([]map[string]any, 25)
+	for i := range rows {
+		rows[i] = map[string]any{"id": float64(i + 1)}
+	}
+	paged := NewTable("Paged", []string{"ID"})
+	paged.Source = TableSourceFunc(func(ctx context.Context, req PageRequest) ([]map[string]any, int, error) {
+		end := min(req.Offset+req.Limit, len(rows))
+		return rows[req.Offset:end], len(rows), nil
+	})
+	paged.SetPage(2)
+	if err := paged.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	progress := NewProgressBar("Conversion")
+	progress.Value = 75
+	progress.MinValue = 10
+	progress.MaxValue = 110
+	progress.ShowPercent = true
+	progress.AddThreshold("low", 30, "#f44336")
+	progress.AddThreshold("high", 200, "#4caf50")
+	progress.AddSegment("Organic", 40, "#2196f3")
+	progress.AddSegment("Paid", 25, "#ffc107")
+	progress.SetTarget(90)
+
+	ranking := NewRanking("Clients")
+	ranking.AddItem(NewRankingItem(1, "Alpha", "Top client", "https://example.com/a.png"))
+	ranking.AddItem(NewRankingItem(2, "Beta", "", ""))
+	ranking.SetOrder("desc")
+
+	indicators := NewGrid("Indicators", 1, 2)
+	indicators.AutoGrow = true
+	indicators.MaxRows = 4
+	indicators.AddNext(sales)
+	indicators.AddNext(failing)
+	indicators.AddNext(hidden)
+
+	charts := NewGrid("Charts", 2, 2)
+	charts.Spacing = 4
+	charts.Padding = 0
+	charts.AddItem(line, 0, 0, 1, 2)
+	charts.AddItem(timeline, 1, 0, 1, 1)
+	charts.AddItem(scatter, 1, 1, 1, 1)
+
+	main := NewGrid("Main", 5, 3)
+	main.AddItem(filters, 0, 0, 1, 3)
+	main.AddItem(indicators, 1, 0, 1, 3)
+	main.AddItem(charts, 2, 0, 1, 2)
+	main.AddItem(legacy, 2, 2, 1, 1)
+	main.AddItem(users, 3, 0, 1, 2)
+	main.AddItem(progress, 3, 2, 1, 1)
+	main.AddItem(paged, 4, 0, 1, 2)
+	main.AddItem(ranking, 4, 2, 1, 1)
+	main.AddBreakpoint("xs", 0, 1)
+	main.AddBreakpoint("sm", 576, 2)
+	if err := main.SetOverride(2, 2, "sm", Placement{Row: 2, Column: 0, RowSpan: 1, ColSpan: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDashboard("Full", "Every widget")
+	d.SetLayout(main)
+	if err := d.Validate(); err != nil {
+		t.Fatalf("fixture is not valid: %v", err)
+	}
+	if _, err := d.ComputeLayout(Size{Width: 1024}); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestWriteJSONMatchesRender(t *testing.T) {
+	d := fullDashboard(t)
+
+	options := []*RenderOptions{
+		nil,
+		{Hidden: HiddenOmit},
+		{Filters: FilterValues{"region": "North"}},
+		{Hidden: HiddenOmit, Filters: FilterValues{"region": "South"}},
+	}
+	for i, opts := range options {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			want, err := json.Marshal(d.RenderWith(opts))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			if err := d.WriteJSON(&buf, &JSONOptions{Render: opts}); err != nil {
+				t.Fatalf("WriteJSON() error = %v", err)
+			}
+			if got := buf.Bytes(); !bytes.Equal(got, want) {
+				t.Errorf("WriteJSON() differs from json.Marshal(RenderWith())\n got: %s\nwant: %s", got, want)
+			}
+		})
+	}
+
+	got, err := d.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(d.Render())
+	if !bytes.Equal(got, want) {
+		t.Errorf("MarshalJSON() differs from json.Marshal(Render())\n got: %s\nwant: %s", got, want)
+	}
+}
+
+// largeDashboard returns a dashboard of 50 tables holding 200 rows each
+func largeDashboard() *Dashboard {
+	g := NewGrid("Tables", 10, 5)
+	for i := 0; i < 50; i++ {
+		table := NewTable(fmt.Sprintf("Table %d", i), []string{"ID", "Name", "Amount", "Date"})
+		table.PageSize = 200
+		for j := 0; j < 200; j++ {
+			table.Data = append(table.Data, map[string]any{
+				"id":     j,
+				"name":   fmt.Sprintf("Row %d", j),
+				"amount": float64(j) * 1.5,
+				"date":   "2025-06-26",
+			})
+		}
+		g.AddNext(table)
+	}
+
+	d := NewDashboard("Large", "")
+	d.SetLayout(g)
+	return d
+}
+
+func BenchmarkWriteJSON(b *testing.B) {
+	d := largeDashboard()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := d.WriteJSON(io.Discard, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRenderMarshal(b *testing.B) {
+	d := largeDashboard()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(d.Render()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,446 @@
+package bussola
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ColumnType is the kind of values held by a table column
+type ColumnType string
+
+const (
+	ColumnString   ColumnType = "string"
+	ColumnNumber   ColumnType = "number"
+	ColumnDate     ColumnType = "date"
+	ColumnCurrency ColumnType = "currency"
+	ColumnPercent  ColumnType = "percent"
+	ColumnBool     ColumnType = "bool"
+	ColumnLink     ColumnType = "link"
+)
+
+// Valid reports whether the column type is one of the supported types
+func (t ColumnType) Valid() bool {
+	switch t {
+	case ColumnString, ColumnNumber, ColumnDate, ColumnCurrency, ColumnPercent, ColumnBool, ColumnLink:
+		return true
+	}
+	return false
+}
+
+// Numeric reports whether the column holds numbers
+func (t ColumnType) Numeric() bool {
+	return t == ColumnNumber || t == ColumnCurrency || t == ColumnPercent
+}
+
+// Align is the horizontal alignment of a table column
+type Align string
+
+const (
+	AlignLeft   Align = "left"
+	AlignCenter Align = "center"
+	AlignRight  Align = "right"
+)
+
+// Column describes a table column. Format depends on Type: a Go time layout
+// for dates, a "yes|no" pair for booleans and a fmt verb such as "R$ %.2f"
+// for the other types.
+type Column struct {
+	Key      string     `json:"key"`
+	Label    string     `json:"label"`
+	Type     ColumnType `json:"type"`
+	Align    Align      `json:"align"`
+	Format   string     `json:"format,omitempty"`
+	Sortable bool       `json:"sortable"`
+	Hidden   bool       `json:"hidden,omitempty"`
+}
+
+// TableSort is the column and direction a table is sorted by
+type TableSort struct {
+	Key  string `json:"key"`
+	Desc bool   `json:"desc"`
+}
+
+// NewColumn creates a sortable column aligned according to its type
+func NewColumn(key, label string, columnType ColumnType) Column {
+	align := AlignLeft
+	switch {
+	case columnType.Numeric():
+		align = AlignRight
+	case columnType == ColumnBool:
+		align = AlignCenter
+	}
+	return Column{Key: key, Label: label, Type: columnType, Align: align, Sortable: true}
+}
+
+// AddColumn appends a column to the table
+func (t *Table) AddColumn(col Column) {
+	t.Columns = append(t.Columns, col)
+}
+
+// ResolvedColumns returns the table columns. Tables without Columns get
+// string columns derived from Headers, keyed by the matching row key or by
+// the header in lowerCamel case ("Last Access" becomes "lastAccess").
+func (t *Table) ResolvedColumns() []Column {
+	if len(t.Columns) > 0 {
+		return t.Columns
+	}
+
+	columns := make([]Column, len(t.Headers))
+	for i, header := range t.Headers {
+		columns[i] = NewColumn(t.headerKey(header), header, ColumnString)
+	}
+	return columns
+}
+
+// VisibleColumns returns the resolved columns that are not hidden
+func (t *Table) VisibleColumns() []Column {
+	columns := []Column{}
+	for _, col := range t.ResolvedColumns() {
+		if !col.Hidden {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+func (t *Table) headerKey(header string) string {
+	want := normalizeKey(header)
+	for _, row := range t.Data {
+		for key := range row {
+			if normalizeKey(key) == want {
+				return key
+			}
+		}
+	}
+	return lowerCamel(header)
+}
+
+// lowerCamel joins the words of s as lowerCamel case
+func lowerCamel(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for i, word := range words {
+		if i == 0 {
+			b.WriteString(strings.ToLower(word))
+			continue
+		}
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
+
+// Column returns the resolved column with the given key
+func (t *Table) Column(key string) (Column, bool) {
+	for _, col := range t.ResolvedColumns() {
+		if col.Key == key {
+			return col, true
+		}
+	}
+	return Column{}, false
+}
+
+// SortBy sorts Data by a sortable column and records it as the table sort.
+// Rows without a value always come last.
+func (t *Table) SortBy(key string, desc bool) error {
+	col, ok := t.Column(key)
+	if !ok {
+		return fmt.Errorf("bussola: table has no column %q", key)
+	}
+	if !col.Sortable {
+		return fmt.Errorf("bussola: column %q is not sortable", key)
+	}
+
+	t.Sort = &TableSort{Key: key, Desc: desc}
+	sortRows(t.Data, col, desc)
+	return nil
+}
+
+// SortedData returns the rows in the order set by Sort, leaving Data as is
+func (t *Table) SortedData() []map[string]any {
+	if t.Sort == nil || len(t.Data) < 2 {
+		return t.Data
+	}
+	col, ok := t.Column(t.Sort.Key)
+	if !ok {
+		return t.Data
+	}
+
+	rows := slices.Clone(t.Data)
+	sortRows(rows, col, t.Sort.Desc)
+	return rows
+}
+
+func sortRows(rows []map[string]any, col Column, desc bool) {
+	slices.SortStableFunc(rows, func(a, b map[string]any) int {
+		va, vb := col.Value(a), col.Value(b)
+		switch {
+		case va == nil && vb == nil:
+			return 0
+		case va == nil:
+			return 1
+		case vb == nil:
+			return -1
+		}
+		c := compareValues(col.Type, va, vb)
+		if desc {
+			return -c
+		}
+		return c
+	})
+}
+
+func compareValues(columnType ColumnType, a, b any) int {
+	switch {
+	case columnType.Numeric():
+		x, okA := toNumber(a)
+		y, okB := toNumber(b)
+		if okA && okB {
+			return compareFloats(x, y)
+		}
+	case columnType == ColumnDate:
+		x, okA := toTime(a)
+		y, okB := toTime(b)
+		if okA && okB {
+			return x.Compare(y)
+		}
+	case columnType == ColumnBool:
+		x, okA := a.(bool)
+		y, okB := b.(bool)
+		if okA && okB {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(strings.ToLower(fmt.Sprint(a)), strings.ToLower(fmt.Sprint(b)))
+}
+
+func compareFloats(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func toNumber(v any) (float64, bool) {
+	if s, ok := v.(string); ok {
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return n, err == nil
+	}
+	return toFloat(v)
+}
+
+func toTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		parsed, err := parseDate(t)
+		return parsed, err == nil
+	}
+	return time.Time{}, false
+}
+
+// Value returns the value of the column in row. Keys are matched exactly
+// first, then ignoring case, spaces and punctuation.
+func (c Column) Value(row map[string]any) any {
+	if v, ok := row[c.Key]; ok {
+		return v
+	}
+	want := normalizeKey(c.Key)
+	for key, v := range row {
+		if normalizeKey(key) == want {
+			return v
+		}
+	}
+	return nil
+}
+
+// FormatValue formats a value of the column for display. Values that do not
+// fit the column type are printed as they are; nil gives "".
+func (c Column) FormatValue(v any) string {
+	if v == nil {
+		return ""
+	}
+
+	switch {
+	case c.Type.Numeric():
+		n, ok := toNumber(v)
+		if !ok {
+			break
+		}
+		switch {
+		case c.Format != "":
+			return fmt.Sprintf(c.Format, n)
+		case c.Type == ColumnCurrency:
+			return strconv.FormatFloat(n, 'f', 2, 64)
+		case c.Type == ColumnPercent:
+			return strconv.FormatFloat(n, 'f', -1, 64) + "%"
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	case c.Type == ColumnDate:
+		t, ok := toTime(v)
+		if !ok {
+			break
+		}
+		if c.Format != "" {
+			return t.Format(c.Format)
+		}
+		return t.Format(DateLayout)
+	case c.Type == ColumnBool:
+		b, ok := v.(bool)
+		if !ok {
+			break
+		}
+		if yes, no, found := strings.Cut(c.Format, "|"); found {
+			if b {
+				return yes
+			}
+			return no
+		}
+		return strconv.FormatBool(b)
+	}
+
+	// Format only applies to strings in string and link columns; its verb
+	// is meant for numbers, dates or booleans in the other types
+	if s, ok := v.(string); ok && c.Format != "" && (c.Type == ColumnString || c.Type == ColumnLink) {
+		return fmt.Sprintf(c.Format, s)
+	}
+	if n, ok := v.(float64); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// PageRequest describes the rows a TableSource must load
+type PageRequest struct {
+	Offset int
+	Limit  int // zero means every row from Offset
+	Sort   *TableSort
+}
+
+// TableSource loads table rows on demand, so large datasets never have to
+// sit in memory. It returns the rows of the requested page, already sorted,
+// and the total number of rows.
+type TableSource interface {
+	LoadPage(ctx context.Context, req PageRequest) (rows []map[string]any, total int, err error)
+}
+
+// TableSourceFunc adapts an ordinary function to the TableSource interface
+type TableSourceFunc func(ctx context.Context, req PageRequest) ([]map[string]any, int, error)
+
+func (f TableSourceFunc) LoadPage(ctx context.Context, req PageRequest) ([]map[string]any, int, error) {
+	return f(ctx, req)
+}
+
+// SetPage selects the page returned by CurrentRows. Tables with a Source
+// must be loaded again.
+func (t *Table) SetPage(n int) {
+	t.CurrentPage = max(n, 1)
+}
+
+// Page returns the rows of page n, counted from 1, of the in-memory Data in
+// the order set by Sort. Pages past the end are empty.
+func (t *Table) Page(n int) []map[string]any {
+	rows := t.SortedData()
+	if t.PageSize <= 0 || rows == nil {
+		return rows
+	}
+
+	start := min(max(n-1, 0)*t.PageSize, len(rows))
+	end := min(start+t.PageSize, len(rows))
+	return rows[start:end]
+}
+
+// CurrentRows returns the rows of the current page: the page loaded from
+// Source when there is one, otherwise the matching slice of Data
+func (t *Table) CurrentRows() []map[string]any {
+	if t.Source != nil {
+		return t.page
+	}
+	return t.Page(t.CurrentPage)
+}
+
+// TotalRows returns the number of rows of the table, as reported by Source
+// when there is one
+func (t *Table) TotalRows() int {
+	if t.Source != nil {
+		return t.total
+	}
+	return len(t.Data)
+}
+
+// TotalPages returns the number of pages, at least 1
+func (t *Table) TotalPages() int {
+	if t.PageSize <= 0 {
+		return 1
+	}
+	return max(1, (t.TotalRows()+t.PageSize-1)/t.PageSize)
+}
+
+// Load fetches the current page from Source. Resolver.Resolve loads every
+// table with a Source, so Load is only needed when rendering without it.
+func (t *Table) Load(ctx context.Context) error {
+	if t.Source == nil {
+		return nil
+	}
+	rows, total, err := t.Source.LoadPage(ctx, t.pageRequest())
+	if err != nil {
+		return err
+	}
+	t.page, t.total = rows, total
+	return nil
+}
+
+func (t *Table) pageRequest() PageRequest {
+	req := PageRequest{Sort: t.Sort}
+	if t.PageSize > 0 {
+		req.Offset = (max(t.CurrentPage, 1) - 1) * t.PageSize
+		req.Limit = t.PageSize
+	}
+	return req
+}
+
+// ErrPartialTable is returned when exporting a paged table decoded from JSON.
+// It only holds the page it was rendered with; set a Source to read the
+// others.
+var ErrPartialTable = errors.New("bussola: decoded table only holds one page of its rows")
+
+// Complete reports whether every row of the table can be read. It is false
+// for a paged table decoded from JSON until a Source is set.
+func (t *Table) Complete() bool {
+	_, partial := t.Source.(*pageSnapshot)
+	return !partial
+}
+
+// pageSnapshot serves the single page a table was decoded with
+type pageSnapshot struct {
+	offset int
+	rows   []map[string]any
+	total  int
+}
+
+func (s *pageSnapshot) LoadPage(ctx context.Context, req PageRequest) ([]map[string]any, int, error) {
+	if req.Offset != s.offset {
+		return nil, s.total, fmt.Errorf("bussola: decoded table only holds the rows at offset %d", s.offset)
+	}
+	return s.rows, s.total, nil
+}
@@ -0,0 +1,109 @@
+package bussola
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestColumnFormatValue(t *testing.T) {
+	column := func(columnType ColumnType, format string) Column {
+		col := NewColumn("key", "Key", columnType)
+		col.Format = format
+		return col
+	}
+	tests := []struct {
+		name  string
+		col   Column
+		value any
+		want  string
+	}{
+		{"nil", column(ColumnString, ""), nil, ""},
+		{"string format", column(ColumnString, "<%s>"), "a", "<a>"},
+		{"link format", column(ColumnLink, "https://%s"), "example.com", "https://example.com"},
+		{"number format", column(ColumnNumber, "R$ %.2f"), 3.14159, "R$ 3.14"},
+		{"number from string", column(ColumnNumber, "R$ %.2f"), "2.5", "R$ 2.50"},
+		{"number mismatch", column(ColumnNumber, "R$ %.2f"), "ab", "ab"},
+		{"currency", column(ColumnCurrency, ""), 3, "3.00"},
+		{"percent", column(ColumnPercent, ""), 12.5, "12.5%"},
+		{"date format", column(ColumnDate, "02/01/2006"), "2024-01-02", "02/01/2024"},
+		{"date from time", column(ColumnDate, "02/01/2006"), time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), "04/03/2024"},
+		{"date mismatch", column(ColumnDate, "02/01/2006"), "n/a", "n/a"},
+		{"bool format", column(ColumnBool, "yes|no"), false, "no"},
+		{"bool mismatch", column(ColumnBool, "yes|no"), "true", "true"},
+		{"bool without format", column(ColumnBool, ""), true, "true"},
+		{"mismatch float", column(ColumnBool, ""), 1.5, "1.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.col.FormatValue(tt.value); got != tt.want {
+				t.Errorf("FormatValue(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableSortBy(t *testing.T) {
+	rows := func() []map[string]any {
+		return []map[string]any{
+			{"name": "b", "amount": 10.0, "when": "2024-01-02", "ok": true},
+			{"name": "A", "amount": nil},
+			{"name": "c", "amount": "2", "when": "2023-05-01", "ok": false},
+			{"name": "d", "amount": "n/a", "when": "soon", "ok": "yes"},
+		}
+	}
+	tests := []struct {
+		name string
+		key  string
+		desc bool
+		want []string
+	}{
+		{"string ignores case", "name", false, []string{"A", "b", "c", "d"}},
+		{"string desc", "name", true, []string{"d", "c", "b", "A"}},
+		{"number mixes types", "amount", false, []string{"c", "b", "d", "A"}},
+		{"number desc keeps nil last", "amount", true, []string{"d", "b", "c", "A"}},
+		{"date", "when", false, []string{"c", "b", "d", "A"}},
+		{"bool", "ok", false, []string{"c", "b", "d", "A"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := NewTable("table", nil)
+			table.Data = rows()
+			table.AddColumn(NewColumn("name", "Name", ColumnString))
+			table.AddColumn(NewColumn("amount", "Amount", ColumnNumber))
+			table.AddColumn(NewColumn("when", "When", ColumnDate))
+			table.AddColumn(NewColumn("ok", "OK", ColumnBool))
+
+			if err := table.SortBy(tt.key, tt.desc); err != nil {
+				t.Fatalf("SortBy() error = %v", err)
+			}
+			var got []string
+			for _, row := range table.Data {
+				got = append(got, row["name"].(string))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+			if table.Sort == nil || table.Sort.Key != tt.key || table.Sort.Desc != tt.desc {
+				t.Errorf("Sort = %+v, want %s desc=%v", table.Sort, tt.key, tt.desc)
+			}
+		})
+	}
+}
+
+func TestTableSortByRejectsColumns(t *testing.T) {
+	table := NewTable("table", nil)
+	col := NewColumn("name", "Name", ColumnString)
+	col.Sortable = false
+	table.AddColumn(col)
+
+	if err := table.SortBy("missing", false); err == nil {
+		t.Error("SortBy() on a missing column succeeded")
+	}
+	if err := table.SortBy("name", false); err == nil {
+		t.Error("SortBy() on an unsortable column succeeded")
+	}
+	if table.Sort != nil {
+		t.Errorf("Sort = %+v, want nil after failed sorts", table.Sort)
+	}
+}
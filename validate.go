@@ -0,0 +1,334 @@
+package bussola
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldError describes a problem with a single field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError collects every problem found while validating a value
+type ValidationError struct {
+	Errors []*FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// validator accumulates field errors while walking a value
+type validator struct {
+	errs []*FieldError
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.errs = append(v.errs, &FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// merge adds the problems reported by err, nesting their fields under prefix
+func (v *validator) merge(prefix string, err error) {
+	if err == nil {
+		return
+	}
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		v.add(prefix, "%v", err)
+		return
+	}
+	for _, fe := range ve.Errors {
+		v.errs = append(v.errs, &FieldError{Field: joinPath(prefix, fe.Field), Message: fe.Message})
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errs}
+}
+
+func indexPath(i int) string {
+	return fmt.Sprintf("[%d]", i)
+}
+
+func joinPath(prefix, field string) string {
+	switch {
+	case prefix == "":
+		return field
+	case field == "":
+		return prefix
+	case strings.HasPrefix(field, "["):
+		return prefix + field
+	}
+	return prefix + "." + field
+}
+
+// Validate checks the whole dashboard: the theme colors and every cell and
+// widget of the layout, including nested grids. Each problem is reported
+// with its path, such as "layout.cells[1][0].content.cells[0][2]".
+func (d *Dashboard) Validate() error {
+	v := &validator{}
+	if d.Theme != nil {
+		v.merge("theme", d.Theme.Validate())
+	}
+	if d.Layout != nil {
+		v.merge("layout", d.Layout.Validate())
+	}
+	return v.err()
+}
+
+// Validate checks that every theme color is a valid hex color
+func (t *Theme) Validate() error {
+	v := &validator{}
+	colors := []struct{ field, value string }{
+		{"primary", t.Primary},
+		{"secondary", t.Secondary},
+		{"background", t.Background},
+		{"textColor", t.TextColor},
+	}
+	for _, c := range colors {
+		if c.value == "" {
+			continue
+		}
+		if _, err := ParseHexColor(c.value); err != nil {
+			v.add(c.field, "%v", err)
+		}
+	}
+	return v.err()
+}
+
+// Validate checks the cells of the grid and the widgets they hold. Unlike
+// PlaceItem it also catches problems in grids built as literals or decoded
+// from JSON: Cells not matching Rows and Columns, missing content, spans
+// running past the grid and overlaps.
+func (g *Grid) Validate() error {
+	v := &validator{}
+	if len(g.Cells) != g.Rows {
+		v.add("cells", "has %d rows but the grid has %d", len(g.Cells), g.Rows)
+	}
+	for i, row := range g.Cells {
+		if len(row) != g.Columns {
+			v.add(fmt.Sprintf("cells[%d]", i), "has %d columns but the grid has %d", len(row), g.Columns)
+		}
+	}
+	if g.MaxRows < 0 {
+		v.add("maxRows", "must not be negative")
+	} else if g.MaxRows > 0 && g.Rows > g.MaxRows {
+		v.add("maxRows", "%d is less than the %d rows of the grid", g.MaxRows, g.Rows)
+	}
+
+	breakpoints := map[string]int{}
+	for i, bp := range g.Breakpoints {
+		field := fmt.Sprintf("breakpoints[%d]", i)
+		if prev, ok := breakpoints[bp.Name]; ok {
+			v.add(field+".name", "%q duplicates breakpoints[%d]", bp.Name, prev)
+		} else if bp.Name == "" {
+			v.add(field+".name", "is required")
+		} else {
+			breakpoints[bp.Name] = i
+		}
+		if bp.Columns <= 0 {
+			v.add(field+".columns", "must be positive, got %d", bp.Columns)
+		}
+		if bp.MinWidth < 0 {
+			v.add(field+".minWidth", "must not be negative, got %g", bp.MinWidth)
+		}
+	}
+
+	claimed := map[cellKey]string{}
+	overridden := map[string]map[cellKey]string{}
+	for i := range g.Cells {
+		for j := range g.Cells[i] {
+			cell := g.Cells[i][j]
+			if cell == nil {
+				continue
+			}
+			field := fmt.Sprintf("cells[%d][%d]", i, j)
+
+			if cell.Row != i || cell.Column != j {
+				v.add(field, "holds a cell positioned at (%d, %d)", cell.Row, cell.Column)
+			}
+			switch {
+			case i >= g.Rows || j >= g.Columns:
+				v.add(field, "is outside the %dx%d grid", g.Rows, g.Columns)
+			case cell.RowSpan <= 0 || cell.ColSpan <= 0:
+				v.add(field, "span %dx%d must be positive", cell.RowSpan, cell.ColSpan)
+			case i+cell.RowSpan > g.Rows || j+cell.ColSpan > g.Columns:
+				v.add(field, "span %dx%d runs past the %dx%d grid", cell.RowSpan, cell.ColSpan, g.Rows, g.Columns)
+			}
+
+			// Spans are clipped to the grid, as in index, so a bad span
+			// costs no more than the grid itself
+			overlaps := map[string]bool{}
+			for r := i; r < min(i+max(cell.RowSpan, 1), g.Rows); r++ {
+				for c := j; c < min(j+max(cell.ColSpan, 1), g.Columns); c++ {
+					key := cellKey{r, c}
+					if other, ok := claimed[key]; ok && !overlaps[other] {
+						overlaps[other] = true
+						v.add(field, "overlaps %s at (%d, %d)", other, r, c)
+						continue
+					}
+					claimed[key] = field
+				}
+			}
+
+			names := make([]string, 0, len(cell.Overrides))
+			for name := range cell.Overrides {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				p, at := cell.Overrides[name], fmt.Sprintf("%s.overrides[%q]", field, name)
+				i, ok := breakpoints[name]
+				switch {
+				case !ok:
+					v.add(at, "names no breakpoint of the grid")
+					continue
+				case p.Row < 0 || p.Column < 0:
+					v.add(at, "position (%d, %d) must not be negative", p.Row, p.Column)
+					continue
+				case p.RowSpan <= 0 || p.ColSpan <= 0:
+					v.add(at, "span %dx%d must be positive", p.RowSpan, p.ColSpan)
+					continue
+				case p.Column+p.ColSpan > g.Breakpoints[i].Columns:
+					v.add(at, "span %dx%d at column %d runs past the %d columns of %q", p.RowSpan, p.ColSpan, p.Column, g.Breakpoints[i].Columns, name)
+					continue
+				case p.RowSpan > g.arrangedRows() || p.Row > g.arrangedRows()-p.RowSpan:
+					v.add(at, "span %dx%d at row %d runs past the %d rows an arrangement can hold", p.RowSpan, p.ColSpan, p.Row, g.arrangedRows())
+					continue
+				}
+
+				if overridden[name] == nil {
+					overridden[name] = map[cellKey]string{}
+				}
+			area:
+				for r := p.Row; r < p.Row+p.RowSpan; r++ {
+					for c := p.Column; c < p.Column+p.ColSpan; c++ {
+						if other, ok := overridden[name][cellKey{r, c}]; ok {
+							v.add(at, "overlaps %s at (%d, %d)", other, r, c)
+							break area
+						}
+						overridden[name][cellKey{r, c}] = at
+					}
+				}
+			}
+
+			if cell.Content == nil {
+				v.add(field+".content", "is nil")
+				continue
+			}
+			if c, ok := cell.Content.(interface{ Validate() error }); ok {
+				v.merge(field+".content", c.Validate())
+			}
+		}
+	}
+	return v.err()
+}
+
+// Validate checks the order and that no two items share a position
+func (r *Ranking) Validate() error {
+	v := &validator{}
+	if r.Order != "asc" && r.Order != "desc" {
+		v.add("order", "must be asc or desc, got %q", r.Order)
+	}
+
+	positions := map[int]int{}
+	for i, item := range r.Items {
+		field := joinPath("items", indexPath(i)) + ".position"
+		if j, ok := positions[item.Position]; ok {
+			v.add(field, "%d duplicates items[%d]", item.Position, j)
+			continue
+		}
+		positions[item.Position] = i
+	}
+	return v.err()
+}
+
+// Validate checks the pagination, the columns and the sort, and that every
+// row key matches a column. Keys match ignoring case, spaces and
+// punctuation, so "lastAccess" matches the header "Last Access".
+func (t *Table) Validate() error {
+	v := &validator{}
+	if t.PageSize <= 0 {
+		v.add("pageSize", "must be positive, got %d", t.PageSize)
+	}
+	if t.CurrentPage < 1 {
+		v.add("currentPage", "must be at least 1, got %d", t.CurrentPage)
+	}
+
+	keys := map[string]bool{}
+	seen := map[string]int{}
+	for i, col := range t.Columns {
+		field := joinPath("columns", indexPath(i))
+		if col.Key == "" {
+			v.add(field+".key", "is required")
+		} else if j, ok := seen[col.Key]; ok {
+			v.add(field+".key", "%q duplicates columns[%d]", col.Key, j)
+		} else {
+			seen[col.Key] = i
+		}
+		if !col.Type.Valid() {
+			v.add(field+".type", "unsupported column type %q", col.Type)
+		}
+		switch col.Align {
+		case AlignLeft, AlignCenter, AlignRight:
+		default:
+			v.add(field+".align", "unsupported alignment %q", col.Align)
+		}
+	}
+
+	for _, col := range t.ResolvedColumns() {
+		keys[normalizeKey(col.Key)] = true
+	}
+	if s := t.Sort; s != nil {
+		if col, ok := t.Column(s.Key); !ok {
+			v.add("sort.key", "table has no column %q", s.Key)
+		} else if !col.Sortable {
+			v.add("sort.key", "column %q is not sortable", s.Key)
+		}
+	}
+
+	for i, row := range t.Data {
+		rowKeys := make([]string, 0, len(row))
+		for key := range row {
+			rowKeys = append(rowKeys, key)
+		}
+		sort.Strings(rowKeys)
+		for _, key := range rowKeys {
+			if !keys[normalizeKey(key)] {
+				v.add(joinPath("data", indexPath(i)), "key %q does not match any column", key)
+			}
+		}
+	}
+	return v.err()
+}
+
+// normalizeKey reduces a header or row key to its lowercase letters and
+// digits, the form used to match one against the other
+func normalizeKey(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package bussola
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGridValidateCellsShape(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells [][]*GridCell
+		field string
+	}{
+		{"fewer rows", make([][]*GridCell, 1), "cells"},
+		{"more rows", [][]*GridCell{make([]*GridCell, 2), make([]*GridCell, 2), make([]*GridCell, 2)}, "cells"},
+		{"shorter row", [][]*GridCell{make([]*GridCell, 2), make([]*GridCell, 1)}, "cells[1]"},
+		{"longer row", [][]*GridCell{make([]*GridCell, 3), make([]*GridCell, 2)}, "cells[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Grid{Rows: 2, Columns: 2, Cells: tt.cells}
+			var ve *ValidationError
+			if err := g.Validate(); !errors.As(err, &ve) || ve.Errors[0].Field != tt.field {
+				t.Fatalf("Validate() = %v, want an error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestPlaceItemOnShortCells(t *testing.T) {
+	g := &Grid{Rows: 2, Columns: 2, Cells: [][]*GridCell{make([]*GridCell, 2)}}
+	if err := g.PlaceItem(NewIndicator("a"), 1, 0, 1, 1); !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("PlaceItem() error = %v, want ErrOutOfRange", err)
+	}
+}
+
+func TestGridValidateHugeSpan(t *testing.T) {
+	g := NewGrid("grid", 2, 2)
+	g.Cells[0][0] = &GridCell{Row: 0, Column: 0, RowSpan: 1 << 30, ColSpan: 1 << 10, Content: NewIndicator("a")}
+	g.Cells[1][1] = &GridCell{Row: 1, Column: 1, RowSpan: 1, ColSpan: 1, Content: NewIndicator("b")}
+
+	done := make(chan error, 1)
+	go func() { done <- g.Validate() }()
+	select {
+	case err := <-done:
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Fatalf("Validate() = %v, want a *ValidationError", err)
+		}
+		msgs := ve.Error()
+		if !strings.Contains(msgs, "runs past") || !strings.Contains(msgs, "overlaps") {
+			t.Errorf("Validate() = %v, want the overflow and the overlap", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Validate() did not return for a huge span")
+	}
+}
+
+func TestDashboardValidatePaths(t *testing.T) {
+	at := func(row, col, rowSpan, colSpan int, content Component) *GridCell {
+		return &GridCell{Row: row, Column: col, RowSpan: rowSpan, ColSpan: colSpan, Content: content}
+	}
+
+	progress := NewProgressBar("progress")
+	progress.MinValue, progress.MaxValue = 10, 10
+
+	ranking := NewRanking("ranking")
+	ranking.AddItem(NewRankingItem(1, "a", "", ""))
+	ranking.AddItem(NewRankingItem(1, "b", "", ""))
+
+	table := NewTable("table", []string{"Name", "Last Access"})
+	table.Data = []map[string]any{
+		{"name": "a", "lastAccess": "today"},
+		{"name": "b", "age": 3},
+	}
+
+	bar := NewFilterBar("filters")
+	bar.AddFilter(NewFilterSelect("Status", "status", nil))
+	bar.AddFilter(NewFilterNumber("Amount", "status", 0, 10))
+
+	inner := NewGrid("inner", 2, 3)
+	inner.Cells[0][0] = at(0, 0, 1, 1, nil)
+	inner.Cells[0][1] = at(0, 1, 1, 1, table)
+	inner.Cells[0][2] = at(0, 2, 1, 2, NewIndicator("wide"))
+	inner.Cells[1][0] = at(1, 0, 1, 2, NewIndicator("left"))
+	inner.Cells[1][1] = at(1, 1, 1, 1, NewIndicator("right"))
+
+	outer := NewGrid("outer", 2, 2)
+	outer.Cells[0][0] = at(0, 0, 1, 1, progress)
+	outer.Cells[0][1] = at(0, 1, 1, 1, ranking)
+	outer.Cells[1][0] = at(1, 0, 1, 1, inner)
+	outer.Cells[1][1] = at(1, 1, 1, 1, bar)
+
+	d := NewDashboard("dashboard", "")
+	d.SetTheme(&Theme{Primary: "#12345", Secondary: "#abc", Background: "blue", TextColor: "#000000"})
+	d.SetLayout(outer)
+
+	want := []string{
+		"theme.primary",
+		"theme.background",
+		"layout.cells[0][0].content.maxValue",
+		"layout.cells[0][1].content.items[1].position",
+		"layout.cells[1][0].content.cells[0][0].content",
+		"layout.cells[1][0].content.cells[0][1].content.data[1]",
+		"layout.cells[1][0].content.cells[0][2]",
+		"layout.cells[1][0].content.cells[1][1]",
+		"layout.cells[1][1].content.filters[0].options",
+		"layout.cells[1][1].content.filters[1].key",
+	}
+	var ve *ValidationError
+	if err := d.Validate(); !errors.As(err, &ve) {
+		t.Fatalf("Validate() = %v, want a *ValidationError", err)
+	}
+	got := make([]string, len(ve.Errors))
+	for i, fe := range ve.Errors {
+		got[i] = fe.Field
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Validate() fields:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	for _, fe := range ve.Errors {
+		if fe.Field == "layout.cells[1][0].content.cells[1][1]" && !strings.Contains(fe.Message, "overlaps cells[1][0]") {
+			t.Errorf("overlap message = %q, want it to name cells[1][0]", fe.Message)
+		}
+	}
+}
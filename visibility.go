@@ -0,0 +1,139 @@
+package bussola
+
+import (
+	"slices"
+	"strconv"
+)
+
+// HiddenMode selects how hidden components are rendered
+type HiddenMode int
+
+const (
+	// HiddenMark emits hidden components with "visible": false
+	HiddenMark HiddenMode = iota
+	// HiddenOmit leaves hidden components out of the output
+	HiddenOmit
+)
+
+// RenderOptions configures RenderWith and the JSON encoding of a dashboard
+type RenderOptions struct {
+	Hidden HiddenMode
+
+	// Filters is the current filter state used to evaluate ShowWhen rules.
+	// When nil the rules are only rendered, leaving them to the client.
+	Filters FilterValues
+}
+
+// VisibilityRule shows a widget only while the filter Key holds one of
+// Values. A rule without Values only requires the filter to be set.
+type VisibilityRule struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+}
+
+// ShowWhen adds a rule showing the widget only while the filter key holds
+// one of values. Every rule of a widget must hold for it to be shown.
+func (w *BaseWidget) ShowWhen(key string, values ...string) {
+	w.rules = append(w.rules, VisibilityRule{Key: key, Values: values})
+}
+
+// Rules returns the visibility rules of the widget
+func (w *BaseWidget) Rules() []VisibilityRule { return w.rules }
+
+// VisibleWith reports whether the widget is shown for the given filter state
+func (w *BaseWidget) VisibleWith(values FilterValues) bool {
+	if w.hidden {
+		return false
+	}
+	for _, rule := range w.rules {
+		if !rule.Matches(values) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether the filter state satisfies the rule
+func (r VisibilityRule) Matches(values FilterValues) bool {
+	value, ok := values[r.Key]
+	if !ok {
+		return false
+	}
+
+	var selected []string
+	switch v := value.(type) {
+	case string:
+		selected = []string{v}
+	case []string:
+		selected = v
+	case float64:
+		selected = []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case bool:
+		selected = []string{strconv.FormatBool(v)}
+	default:
+		return value != nil && len(r.Values) == 0
+	}
+
+	for _, s := range selected {
+		if s != "" && (len(r.Values) == 0 || slices.Contains(r.Values, s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Visible reports whether c is shown with these options. Hidden components
+// are never shown; ShowWhen rules are checked when Filters is set.
+func (o *RenderOptions) Visible(c Component) bool {
+	switch c := c.(type) {
+	case ruled:
+		if o != nil && o.Filters != nil {
+			return c.VisibleWith(o.Filters)
+		}
+		return c.Visible()
+	case CanvasObject:
+		return c.Visible()
+	}
+	return true
+}
+
+// ruled is implemented by the components that accept ShowWhen rules
+type ruled interface {
+	CanvasObject
+	Rules() []VisibilityRule
+	VisibleWith(values FilterValues) bool
+}
+
+// framed is implemented by the components positioned by ComputeLayout
+type framed interface {
+	Frame() Rect
+}
+
+func (o *RenderOptions) omit(c Component) bool {
+	return o != nil && o.Hidden == HiddenOmit && !o.Visible(c)
+}
+
+// RenderComponent renders c as it appears in the cells of a grid rendered
+// with opts: with its frame, its ShowWhen rules and its visibility state,
+// and with opts applied to the cells of nested grids
+func RenderComponent(c Component, opts *RenderOptions) map[string]any {
+	if nested, ok := c.(*Grid); ok {
+		return nested.RenderWith(opts)
+	}
+	return decorateLayout(c.Render(), c, opts)
+}
+
+// decorateLayout adds the visibility state of a component and the frame
+// given by ComputeLayout to its rendered form
+func decorateLayout(m map[string]any, c Component, opts *RenderOptions) map[string]any {
+	if f, ok := c.(framed); ok && f.Frame() != (Rect{}) {
+		m["frame"] = f.Frame()
+	}
+	if w, ok := c.(ruled); ok && len(w.Rules()) > 0 {
+		m["showWhen"] = w.Rules()
+	}
+	if !opts.Visible(c) {
+		m["visible"] = false
+	}
+	return m
+}
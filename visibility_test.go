@@ -0,0 +1,91 @@
+package bussola
+
+import "testing"
+
+func visibilityGrid() *Grid {
+	shown, hidden := NewIndicator("shown"), NewIndicator("hidden")
+	hidden.Hide()
+	g := NewGrid("grid", 1, 2)
+	g.AddItem(shown, 0, 0, 1, 1)
+	g.AddItem(hidden, 0, 1, 1, 1)
+	return g
+}
+
+func renderedCells(m map[string]any) []map[string]any {
+	return m["cells"].([]map[string]any)
+}
+
+func TestRenderWithHiddenOmit(t *testing.T) {
+	g := visibilityGrid()
+
+	cells := renderedCells(g.RenderWith(&RenderOptions{Hidden: HiddenOmit}))
+	if len(cells) != 1 {
+		t.Fatalf("rendered %d cells, want the hidden one omitted", len(cells))
+	}
+	if title := cells[0]["content"].(map[string]any)["title"]; title != "shown" {
+		t.Errorf("rendered cell %v, want the shown indicator", title)
+	}
+}
+
+func TestRenderWithHiddenMark(t *testing.T) {
+	g := visibilityGrid()
+
+	for _, opts := range []*RenderOptions{nil, {Hidden: HiddenMark}} {
+		cells := renderedCells(g.RenderWith(opts))
+		if len(cells) != 2 {
+			t.Fatalf("rendered %d cells, want both", len(cells))
+		}
+		if _, ok := cells[0]["content"].(map[string]any)["visible"]; ok {
+			t.Error("shown indicator carries a visible key")
+		}
+		if visible, ok := cells[1]["content"].(map[string]any)["visible"]; !ok || visible != false {
+			t.Errorf("hidden indicator visible = %v, want false", visible)
+		}
+	}
+}
+
+func TestShowWhen(t *testing.T) {
+	w := NewIndicator("w")
+	w.ShowWhen("status", "active", "pending")
+	g := NewGrid("grid", 1, 1)
+	g.AddItem(w, 0, 0, 1, 1)
+
+	tests := []struct {
+		name    string
+		filters FilterValues
+		visible bool
+	}{
+		{"matching", FilterValues{"status": "active"}, true},
+		{"matching one of many", FilterValues{"status": []string{"closed", "pending"}}, true},
+		{"not matching", FilterValues{"status": "closed"}, false},
+		{"empty value", FilterValues{"status": ""}, false},
+		{"missing filter", FilterValues{"other": "active"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.VisibleWith(tt.filters); got != tt.visible {
+				t.Errorf("VisibleWith() = %v, want %v", got, tt.visible)
+			}
+
+			cells := renderedCells(g.RenderWith(&RenderOptions{Hidden: HiddenOmit, Filters: tt.filters}))
+			if got := len(cells) == 1; got != tt.visible {
+				t.Errorf("HiddenOmit rendered %d cells, want visible = %v", len(cells), tt.visible)
+			}
+
+			cells = renderedCells(g.RenderWith(&RenderOptions{Filters: tt.filters}))
+			content := cells[0]["content"].(map[string]any)
+			if _, ok := content["showWhen"]; !ok {
+				t.Error("rendered widget has no showWhen rules")
+			}
+			if _, marked := content["visible"]; marked == tt.visible {
+				t.Errorf("HiddenMark visible key present = %v, want %v", marked, !tt.visible)
+			}
+		})
+	}
+
+	// Without a filter state the rules are left to the client
+	content := renderedCells(g.Render())[0]["content"].(map[string]any)
+	if _, ok := content["visible"]; ok {
+		t.Error("Render() marked a ruled widget hidden without a filter state")
+	}
+}
@@ -2,30 +2,68 @@ package bussola
 
 // BaseWidget provides common widget functionality
 type BaseWidget struct {
+	minSize  Size
 	size     Size
 	position Position
 	hidden   bool
+	rules    []VisibilityRule
+	binding  *Binding
+	err      error
 }
 
-func (w *BaseWidget) MinSize() Size      { return w.size }
+// MinSize returns the size set by SetMinSize, zero by default. It used to
+// return the size set by Resize, which Size now returns.
+func (w *BaseWidget) MinSize() Size { return w.minSize }
+
 func (w *BaseWidget) Resize(size Size)   { w.size = size }
+func (w *BaseWidget) Size() Size         { return w.size }
 func (w *BaseWidget) Position() Position { return w.position }
 func (w *BaseWidget) Move(pos Position)  { w.position = pos }
 func (w *BaseWidget) Visible() bool      { return !w.hidden }
 func (w *BaseWidget) Show()              { w.hidden = false }
 func (w *BaseWidget) Hide()              { w.hidden = true }
 
-// Chart represents a chart widget
-type Chart struct {
-	BaseWidget
-	Type     string `json:"type"` // line, bar, pie, etc
-	Data     any    `json:"data"`
-	Options  any    `json:"options"`
-	Title    string `json:"title"`
-	Subtitle string `json:"subtitle"`
+// SetMinSize sets the size the layout pass never shrinks the widget below
+func (w *BaseWidget) SetMinSize(size Size) { w.minSize = size }
+
+// Frame returns the rectangle given to the widget by the last layout pass
+func (w *BaseWidget) Frame() Rect {
+	return Rect{X: w.position.X, Y: w.position.Y, Width: w.size.Width, Height: w.size.Height}
+}
+
+// Bind ties the widget to a data source used by Resolver
+func (w *BaseWidget) Bind(b Binding) { w.binding = &b }
+
+// Err returns the error from the last data resolution, if any
+func (w *BaseWidget) Err() error { return w.err }
+
+// decorate adds the state shared by every widget to its rendered form
+func (w *BaseWidget) decorate(m map[string]any) map[string]any {
+	if w.err != nil {
+		m["error"] = w.err.Error()
+	}
+	return m
 }
 
-func NewChart(title string, chartType string) *Chart {
+// Chart represents a chart widget. Series, XAxis and YAxis are the typed
+// model; Data and Options remain for free-form chart data.
+type Chart struct {
+	BaseWidget
+	Type     ChartType `json:"type"`
+	Data     any       `json:"data"`
+	Options  any       `json:"options"`
+	Title    string    `json:"title"`
+	Subtitle string    `json:"subtitle"`
+	Series   []Series  `json:"series"`
+	XAxis    *XAxis    `json:"xAxis"`
+	YAxis    *YAxis    `json:"yAxis"`
+	Stacked  bool      `json:"stacked"`
+}
+
+// NewChart creates a chart of the given type. The type used to be a plain
+// string; callers holding it in a string variable convert it with
+// ChartType(kind), and untyped constants such as "line" still compile.
+func NewChart(title string, chartType ChartType) *Chart {
 	return &Chart{
 		Title: title,
 		Type:  chartType,
@@ -33,24 +71,38 @@ func NewChart(title string, chartType string) *Chart {
 }
 
 func (c *Chart) Render() map[string]any {
-	return map[string]any{
+	n := c.Normalized()
+	return c.decorate(map[string]any{
 		"type":      "chart",
 		"title":     c.Title,
 		"subtitle":  c.Subtitle,
 		"chartType": c.Type,
 		"data":      c.Data,
 		"options":   c.Options,
-	}
+		"series":    n.Series,
+		"xAxis":     n.XAxis,
+		"yAxis":     n.YAxis,
+		"stacked":   c.Stacked,
+	})
 }
 
-// Table represents a table widget with pagination
+// Table represents a table widget with pagination. Columns is the typed
+// column model; tables without it derive plain columns from Headers. Rows
+// come from Data or, page by page, from Source.
 type Table struct {
 	BaseWidget
 	Headers     []string         `json:"headers"`
+	Columns     []Column         `json:"columns"`
 	Data        []map[string]any `json:"data"`
+	Source      TableSource      `json:"-"`
+	Sort        *TableSort       `json:"sort,omitempty"`
 	PageSize    int              `json:"pageSize"`
 	CurrentPage int              `json:"currentPage"`
 	Title       string           `json:"title"`
+
+	// page and total hold the last page loaded from Source
+	page  []map[string]any
+	total int
 }
 
 func NewTable(title string, headers []string) *Table {
@@ -63,14 +115,21 @@ func NewTable(title string, headers []string) *Table {
 }
 
 func (t *Table) Render() map[string]any {
-	return map[string]any{
+	result := map[string]any{
 		"type":        "table",
 		"title":       t.Title,
 		"headers":     t.Headers,
-		"data":        t.Data,
+		"columns":     t.ResolvedColumns(),
+		"data":        t.CurrentRows(),
 		"pageSize":    t.PageSize,
 		"currentPage": t.CurrentPage,
+		"totalRows":   t.TotalRows(),
+		"totalPages":  t.TotalPages(),
+	}
+	if t.Sort != nil {
+		result["sort"] = t.Sort
 	}
+	return t.decorate(result)
 }
 
 // Indicator represents a numeric indicator widget
@@ -90,7 +149,7 @@ func NewIndicator(title string) *Indicator {
 }
 
 func (i *Indicator) Render() map[string]any {
-	return map[string]any{
+	return i.decorate(map[string]any{
 		"type":        "indicator",
 		"title":       i.Title,
 		"value":       i.Value,
@@ -98,16 +157,21 @@ func (i *Indicator) Render() map[string]any {
 		"unit":        i.Unit,
 		"trend":       i.Trend,
 		"description": i.Description,
-	}
+	})
 }
 
-// ProgressBar represents a progress bar widget
+// ProgressBar represents a progress bar widget. Thresholds, Segments and
+// Target are optional, see progress.go.
 type ProgressBar struct {
 	BaseWidget
-	Title       string  `json:"title"`
-	Value       float64 `json:"value"`
-	MaxValue    float64 `json:"maxValue"`
-	ShowPercent bool    `json:"showPercent"`
+	Title       string      `json:"title"`
+	Value       float64     `json:"value"`
+	MinValue    float64     `json:"minValue"`
+	MaxValue    float64     `json:"maxValue"`
+	ShowPercent bool        `json:"showPercent"`
+	Thresholds  []Threshold `json:"thresholds,omitempty"`
+	Segments    []Segment   `json:"segments,omitempty"`
+	Target      *float64    `json:"target,omitempty"`
 }
 
 // NewProgressBar create a new progress bar
@@ -120,14 +184,41 @@ func NewProgressBar(title string) *ProgressBar {
 }
 
 func (p *ProgressBar) Render() map[string]any {
-	return map[string]any{
+	result := map[string]any{
 		"type":        "progressBar",
 		"title":       p.Title,
 		"value":       p.Value,
 		"maxValue":    p.MaxValue,
 		"showPercent": p.ShowPercent,
-		"percent":     (p.Value / p.MaxValue) * 100,
+		"percent":     p.Percent(),
+	}
+	if p.MinValue != 0 {
+		result["minValue"] = p.MinValue
+	}
+	if len(p.Thresholds) > 0 {
+		result["thresholds"] = p.Thresholds
+		if band := p.Band(); band != nil {
+			result["band"] = band.Name
+			result["color"] = band.Color
+		}
 	}
+	if len(p.Segments) > 0 {
+		segments := []map[string]any{}
+		for _, s := range p.Segments {
+			segments = append(segments, map[string]any{
+				"name":    s.Name,
+				"value":   s.Value,
+				"color":   s.Color,
+				"percent": p.percentOf(s.Value),
+			})
+		}
+		result["segments"] = segments
+	}
+	if p.Target != nil {
+		result["target"] = *p.Target
+		result["targetPercent"] = p.percentOf(*p.Target - p.MinValue)
+	}
+	return p.decorate(result)
 }
 
 type FilterBar struct {
@@ -184,6 +275,7 @@ type FilterSelect struct {
 	Label   string   `json:"label"`
 	Key     string   `json:"key"`
 	Options []string `json:"options"`
+	Default string   `json:"default,omitempty"`
 }
 
 func NewFilterSelect(label, key string, options []string) *FilterSelect {
@@ -191,12 +283,16 @@ func NewFilterSelect(label, key string, options []string) *FilterSelect {
 }
 
 func (f *FilterSelect) Render() map[string]any {
-	return map[string]any{
+	result := map[string]any{
 		"type":    "select",
 		"label":   f.Label,
 		"key":     f.Key,
 		"options": f.Options,
 	}
+	if f.Default != "" {
+		result["default"] = f.Default
+	}
+	return result
 }
 
 type FilterText struct {
@@ -279,6 +375,7 @@ type FilterCheckbox struct {
 	Label   string   `json:"label"`
 	Key     string   `json:"key"`
 	Options []string `json:"options"`
+	Default []string `json:"default,omitempty"`
 }
 
 func NewFilterCheckbox(label, key string, options []string) *FilterCheckbox {
@@ -286,18 +383,23 @@ func NewFilterCheckbox(label, key string, options []string) *FilterCheckbox {
 }
 
 func (f *FilterCheckbox) Render() map[string]any {
-	return map[string]any{
+	result := map[string]any{
 		"type":    "checkbox",
 		"label":   f.Label,
 		"key":     f.Key,
 		"options": f.Options,
 	}
+	if f.Default != nil {
+		result["default"] = f.Default
+	}
+	return result
 }
 
 type FilterRadio struct {
 	Label   string   `json:"label"`
 	Key     string   `json:"key"`
 	Options []string `json:"options"`
+	Default string   `json:"default,omitempty"`
 }
 
 func NewFilterRadio(label, key string, options []string) *FilterRadio {
@@ -305,18 +407,23 @@ func NewFilterRadio(label, key string, options []string) *FilterRadio {
 }
 
 func (f *FilterRadio) Render() map[string]any {
-	return map[string]any{
+	result := map[string]any{
 		"type":    "radio",
 		"label":   f.Label,
 		"key":     f.Key,
 		"options": f.Options,
 	}
+	if f.Default != "" {
+		result["default"] = f.Default
+	}
+	return result
 }
 
 type FilterMultiSelect struct {
 	Label   string   `json:"label"`
 	Key     string   `json:"key"`
 	Options []string `json:"options"`
+	Default []string `json:"default,omitempty"`
 }
 
 func NewFilterMultiSelect(label, key string, options []string) *FilterMultiSelect {
@@ -324,12 +431,16 @@ func NewFilterMultiSelect(label, key string, options []string) *FilterMultiSelec
 }
 
 func (f *FilterMultiSelect) Render() map[string]any {
-	return map[string]any{
+	result := map[string]any{
 		"type":    "multiSelect",
 		"label":   f.Label,
 		"key":     f.Key,
 		"options": f.Options,
 	}
+	if f.Default != nil {
+		result["default"] = f.Default
+	}
+	return result
 }
 
 type FilterSlider struct {
@@ -464,10 +575,10 @@ func (r *Ranking) Render() map[string]any {
 		}
 		items = append(items, item)
 	}
-	return map[string]any{
+	return r.decorate(map[string]any{
 		"type":  "ranking",
 		"title": r.Title,
 		"order": r.Order,
 		"items": items,
-	}
+	})
 }